@@ -0,0 +1,204 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// WriteExample writes a .env style template for every registered environment variable to w, so that an
+// always-current .env.example can be generated from code. Each variable is written as a comment with its
+// help text, a "# REQUIRED" comment if it is required, and a NAME=<default> line. The output can be read
+// back with LoadFile.
+//
+// Example output:
+//
+//	# HTTP server port
+//	PORT=8080
+//
+//	# API key for upstream server
+//	# REQUIRED
+//	API_KEY=
+func WriteExample(w io.Writer) error {
+	return defaultRegistry.WriteExample(w)
+}
+
+// WriteExample is like the package-level WriteExample but describes the variables registered with r.
+func (r *Registry) WriteExample(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.envs {
+		lines := make([]string, 0)
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		if e.help != "" {
+			lines = append(lines, "# "+e.help)
+		}
+		if e.required {
+			lines = append(lines, "# REQUIRED")
+		}
+		// Leave secret defaults out of the template entirely.
+		def := ""
+		if !e.secret {
+			def = e.format(e.defaultValue)
+		}
+		lines = append(lines, r.envName(e)+"="+quoteDotenv(def))
+
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteMarkdown writes a Markdown table describing every registered environment variable to w, with
+// Name, Type, Required, Default and Description columns. Rows are sorted by name so that generated
+// documentation diffs cleanly, and pipe characters are escaped so they do not break the table.
+func WriteMarkdown(w io.Writer) error {
+	return defaultRegistry.WriteMarkdown(w)
+}
+
+// WriteMarkdown is like the package-level WriteMarkdown but describes the variables registered with r.
+func (r *Registry) WriteMarkdown(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vars := slices.Clone(r.envs)
+	slices.SortStableFunc(vars, func(a, b envVar) int {
+		return strings.Compare(r.envName(a), r.envName(b))
+	})
+
+	rows := []string{
+		"| Name | Type | Required | Default | Description |",
+		"| --- | --- | --- | --- | --- |",
+	}
+	for _, e := range vars {
+		required := "no"
+		if e.required {
+			required = "yes"
+		}
+
+		def := e.formatDefault()
+		if def != "" {
+			def = "`" + def + "`"
+		}
+
+		rows = append(rows, "| "+strings.Join([]string{
+			escapeMarkdown(r.envName(e)),
+			escapeMarkdown(e.varType),
+			required,
+			escapeMarkdown(def),
+			escapeMarkdown(e.help),
+		}, " | ")+" |")
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(rows, "\n"))
+	return err
+}
+
+// schemaVar is the JSON description of a variable written by WriteSchemaJSON.
+type schemaVar struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Default  string `json:"default"`
+	Help     string `json:"help"`
+	Secret   bool   `json:"secret,omitempty"`
+}
+
+// WriteSchemaJSON writes a JSON array describing every registered environment variable to w, i.e. to feed a
+// configuration editor or a CI check. Each object has name, type, required, default and help fields, where the
+// default is written as it would be in the environment, or "" for none. Secret variables also have "secret":
+// true, and their defaults are shown as "****". Objects are sorted by name so that the output is stable.
+//
+// Example output:
+//
+//	[
+//	  {
+//	    "name": "PORT",
+//	    "type": "integer",
+//	    "required": false,
+//	    "default": "8080",
+//	    "help": "HTTP server port"
+//	  }
+//	]
+func WriteSchemaJSON(w io.Writer) error {
+	return defaultRegistry.WriteSchemaJSON(w)
+}
+
+// WriteSchemaJSON is like the package-level WriteSchemaJSON but describes the variables registered with r.
+func (r *Registry) WriteSchemaJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schema := make([]schemaVar, 0, len(r.envs))
+	for _, e := range r.envs {
+		schema = append(schema, schemaVar{
+			Name:     r.envName(e),
+			Type:     e.varType,
+			Required: e.required,
+			Default:  e.formatDefault(),
+			Help:     e.help,
+			Secret:   e.secret,
+		})
+	}
+	slices.SortStableFunc(schema, func(a, b schemaVar) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+// escapeMarkdown escapes pipes and flattens newlines so that s fits in a single Markdown table cell.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+}
+
+// redacted replaces the value of secret variables in generated output.
+const redacted = "****"
+
+// formatDefault renders the default value of e, redacting it if e is a secret.
+func (e envVar) formatDefault() string {
+	def := e.format(e.defaultValue)
+	if e.secret && def != "" {
+		return redacted
+	}
+
+	return def
+}
+
+// format renders v as it would be written in the environment for e.
+func (e envVar) format(v interface{}) string {
+	if e.formatValue != nil {
+		return e.formatValue(v)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// formatCurrent renders the current value of e as it would be written in the environment.
+func (e envVar) formatCurrent() string {
+	// Values such as *url.URL and *time.Duration know how to render themselves.
+	if s, ok := e.value.(fmt.Stringer); ok && e.formatValue == nil {
+		return s.String()
+	}
+
+	return e.format(e.get())
+}
+
+// quoteDotenv double quotes s if it would not otherwise be read back unchanged from a .env file.
+func quoteDotenv(s string) string {
+	if s == "" || (!strings.ContainsAny(s, "\"'#\\\n\r\t") && strings.TrimSpace(s) == s) {
+		return s
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
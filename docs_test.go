@@ -0,0 +1,102 @@
+package env
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteExample(t *testing.T) {
+	Reset()
+	Int("PORT", false, 8080, "HTTP server port")
+	String("API_KEY", true, "", "API key for upstream server")
+	StringSlice("ORIGINS", false, []string{"a.com", "b.com"}, ";", "")
+	String("GREETING", false, "hello # world", "Greeting shown to users")
+	Duration("TIMEOUT", true, 5*time.Second, "Request timeout")
+
+	var b strings.Builder
+	assert.NoError(t, WriteExample(&b))
+
+	assert.Equal(t, `# HTTP server port
+PORT=8080
+
+# API key for upstream server
+# REQUIRED
+API_KEY=
+
+ORIGINS=a.com;b.com
+
+# Greeting shown to users
+GREETING="hello # world"
+
+# Request timeout
+# REQUIRED
+TIMEOUT=5s
+`, b.String())
+}
+
+func TestWriteExampleRoundTrip(t *testing.T) {
+	Reset()
+	String("GREETING", false, "say \"hi\"\n", "")
+
+	var b strings.Builder
+	assert.NoError(t, WriteExample(&b))
+
+	pairs, err := parseDotenv(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, []dotenvPair{{"GREETING", "say \"hi\"\n"}}, pairs)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	Reset()
+	String("SERVER_URI", true, "localhost:8181", "URI for upstream server")
+	Int("TIMEOUT", false, 12, "Timeout | in seconds")
+	String("API_KEY", false, "", "API key")
+
+	var b strings.Builder
+	assert.NoError(t, WriteMarkdown(&b))
+
+	assert.Equal(t, `| Name | Type | Required | Default | Description |
+| --- | --- | --- | --- | --- |
+| API_KEY | string | no |  | API key |
+| SERVER_URI | string | yes | `+"`localhost:8181`"+` | URI for upstream server |
+| TIMEOUT | integer | no | `+"`12`"+` | Timeout \| in seconds |
+`, b.String())
+}
+
+func TestWriteSchemaJSON(t *testing.T) {
+	Reset()
+	Int("PORT", false, 8080, "HTTP server port")
+	String("API_KEY", true, "dev-key", "Upstream API key", Secret())
+
+	var b strings.Builder
+	assert.NoError(t, WriteSchemaJSON(&b))
+	assert.Equal(t, `[
+  {
+    "name": "API_KEY",
+    "type": "string",
+    "required": true,
+    "default": "****",
+    "help": "Upstream API key",
+    "secret": true
+  },
+  {
+    "name": "PORT",
+    "type": "integer",
+    "required": false,
+    "default": "8080",
+    "help": "HTTP server port"
+  }
+]
+`, b.String())
+
+	// The output decodes and encodes back unchanged.
+	var schema []schemaVar
+	assert.NoError(t, json.Unmarshal([]byte(b.String()), &schema))
+	out, err := json.MarshalIndent(schema, "", "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, b.String(), string(out)+"\n")
+}
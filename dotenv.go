@@ -0,0 +1,216 @@
+package env
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// dotenvPair is a single KEY=VALUE assignment read from a .env file.
+type dotenvPair struct {
+	key   string
+	value string
+}
+
+// LoadFile reads KEY=VALUE lines from the .env file at path and sets each one in the process
+// environment, so that a subsequent Parse() picks them up. Unless overwrite is true, variables
+// that are already set in the environment are left untouched. When a key is repeated in the file
+// the last value wins.
+//
+// Values set by an earlier LoadFile count as already set, so when loading several files in turn
+// without overwrite the first file to set a key wins, and files should be loaded from the most to
+// the least specific, i.e. .env.local before .env. With overwrite, the last file to set a key wins
+// and replaces the process environment too. Variables registered with the Accumulate option use
+// every value of a repeated key instead of the last.
+//
+// Blank lines and lines starting with # are ignored, and an optional leading "export " is
+// accepted. Values may be wrapped in single quotes (taken literally) or double quotes (where
+// \n, \r, \t, \" and \\ escapes are expanded). Unquoted values have surrounding whitespace and
+// any trailing " # comment" removed.
+//
+// Example .env file:
+//
+//	# HTTP server
+//	BIND_ADDRESS=localhost
+//	BIND_PORT=9090
+//	GREETING="hello, world" # quoted values may contain '#'
+func LoadFile(path string, overwrite bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pairs, err := parseDotenv(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return setPairs(pairs, overwrite)
+}
+
+// LoadFirst loads the first of paths that exists with LoadFile, without overwriting the environment, and
+// returns the path that was used, so that one binary can adapt to different layouts, i.e. .env.local
+// during development and /etc/app/env in production. If none of the paths exist, it returns "" and no
+// error. Any other error, such as a file that cannot be read or parsed, is returned along with its path.
+//
+// Example:
+//
+//	path, err := env.LoadFirst(".env.local", ".env", "/etc/app/env")
+func LoadFirst(paths ...string) (string, error) {
+	for _, path := range paths {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		return path, LoadFile(path, false)
+	}
+
+	return "", nil
+}
+
+// LoadReader reads KEY=VALUE lines from r, using the same format as LoadFile, and sets each one in the
+// process environment. Unless overwrite is true, variables that are already set are left untouched.
+// This suits configuration fetched at runtime, such as a .env blob downloaded over the network.
+func LoadReader(r io.Reader, overwrite bool) error {
+	pairs, err := parseDotenv(r)
+	if err != nil {
+		return err
+	}
+
+	return setPairs(pairs, overwrite)
+}
+
+// loadedValues maps the names set by LoadFile and LoadReader to every value they set in the last call that
+// set them, in order, so that Source can tell them apart from values set in the environment directly and the
+// Accumulate option can recover the values of repeated keys. The last value is the one in the environment.
+var loadedValues sync.Map
+
+// setPairs sets each pair in the process environment. Unless overwrite is true, keys that
+// were already present in the environment before the call are skipped.
+func setPairs(pairs []dotenvPair, overwrite bool) error {
+	// Record which keys exist before loading, so that later duplicates in the same
+	// source still overwrite earlier ones.
+	existing := make(map[string]bool)
+	for _, p := range pairs {
+		if _, ok := os.LookupEnv(p.key); ok {
+			existing[p.key] = true
+		}
+	}
+
+	values := make(map[string][]string)
+	for _, p := range pairs {
+		if existing[p.key] && !overwrite {
+			continue
+		}
+
+		if err := os.Setenv(p.key, p.value); err != nil {
+			return err
+		}
+		values[p.key] = append(values[p.key], p.value)
+		loadedValues.Store(p.key, values[p.key])
+	}
+
+	return nil
+}
+
+// parseDotenv reads every KEY=VALUE assignment from r, in order.
+func parseDotenv(r io.Reader) ([]dotenvPair, error) {
+	pairs := make([]dotenvPair, 0)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		p, ok, err := parseDotenvLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if ok {
+			pairs = append(pairs, p)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// parseDotenvLine parses a single line of a .env file. It returns false if the line is blank or a comment.
+func parseDotenvLine(line string) (dotenvPair, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return dotenvPair{}, false, nil
+	}
+
+	line = strings.TrimPrefix(line, "export ")
+
+	key, value, ok := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return dotenvPair{}, false, fmt.Errorf("expected KEY=VALUE, got %q", line)
+	}
+
+	value, err := parseDotenvValue(strings.TrimSpace(value))
+	if err != nil {
+		return dotenvPair{}, false, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return dotenvPair{key, value}, true, nil
+}
+
+// parseDotenvValue unquotes a raw .env value, or strips any trailing comment if it is unquoted.
+func parseDotenvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch quote := raw[0]; quote {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", raw)
+		}
+
+		return raw[1 : end+1], nil
+
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+
+		return "", fmt.Errorf("unterminated quoted value %s", raw)
+	}
+
+	// Strip a trailing comment from an unquoted value.
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	return strings.TrimSpace(raw), nil
+}
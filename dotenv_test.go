@@ -0,0 +1,163 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), ".env")
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+
+	return path
+}
+
+func TestParseDotenv(t *testing.T) {
+	pairs, err := parseDotenv(strings.NewReader(`
+# a comment
+PLAIN=value
+SPACED =  padded value   
+export EXPORTED=yes
+COMMENTED=value # trailing comment
+DOUBLE="hello, # world\n"
+SINGLE='literal \n'
+EMPTY=
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []dotenvPair{
+		{"PLAIN", "value"},
+		{"SPACED", "padded value"},
+		{"EXPORTED", "yes"},
+		{"COMMENTED", "value"},
+		{"DOUBLE", "hello, # world\n"},
+		{"SINGLE", `literal \n`},
+		{"EMPTY", ""},
+	}, pairs)
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	_, err := parseDotenv(strings.NewReader("OK=1\nnot an assignment"))
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = parseDotenv(strings.NewReader(`KEY="unterminated`))
+	assert.ErrorContains(t, err, "unterminated")
+}
+
+func TestLoadFile(t *testing.T) {
+	cleanupA := setEnv("LOAD_A", "from env")
+	defer cleanupA()
+	defer os.Unsetenv("LOAD_B")
+
+	path := writeFile(t, "LOAD_A=from file\nLOAD_B=first\nLOAD_B=second\n")
+
+	assert.NoError(t, LoadFile(path, false))
+	assert.Equal(t, "from env", os.Getenv("LOAD_A"))
+	assert.Equal(t, "second", os.Getenv("LOAD_B"))
+}
+
+func TestLoadFileOverwrite(t *testing.T) {
+	cleanupA := setEnv("LOAD_A", "from env")
+	defer cleanupA()
+
+	local := writeFile(t, "LOAD_A=from local\n")
+	shared := writeFile(t, "LOAD_A=from shared\n")
+
+	assert.NoError(t, LoadFile(local, true))
+	assert.Equal(t, "from local", os.Getenv("LOAD_A"))
+
+	// Without overwrite, a value loaded by an earlier call is kept.
+	assert.NoError(t, LoadFile(shared, false))
+	assert.Equal(t, "from local", os.Getenv("LOAD_A"))
+
+	assert.NoError(t, LoadFile(shared, true))
+	assert.Equal(t, "from shared", os.Getenv("LOAD_A"))
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	err := LoadFile(filepath.Join(t.TempDir(), "missing.env"), false)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadReader(t *testing.T) {
+	cleanupA := setEnv("LOAD_A", "from env")
+	defer cleanupA()
+	defer os.Unsetenv("LOAD_B")
+
+	err := LoadReader(strings.NewReader("# comment\nLOAD_A=from reader\nLOAD_B='quoted value'\n"), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "from env", os.Getenv("LOAD_A"))
+	assert.Equal(t, "quoted value", os.Getenv("LOAD_B"))
+}
+
+func TestLoadReaderOverwrite(t *testing.T) {
+	cleanup := setEnv("LOAD_A", "from env")
+	defer cleanup()
+
+	err := LoadReader(strings.NewReader("LOAD_A=from reader"), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "from reader", os.Getenv("LOAD_A"))
+}
+
+func TestLoadReaderError(t *testing.T) {
+	err := LoadReader(strings.NewReader("LOAD_A"), false)
+
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestLoadFirst(t *testing.T) {
+	os.Unsetenv("LOAD_FIRST")
+	defer os.Unsetenv("LOAD_FIRST")
+
+	missing := filepath.Join(t.TempDir(), ".env.local")
+	path := writeFile(t, "LOAD_FIRST=second\n")
+	other := writeFile(t, "LOAD_FIRST=third\n")
+
+	used, err := LoadFirst(missing, path, other)
+	assert.NoError(t, err)
+	assert.Equal(t, path, used)
+	assert.Equal(t, "second", os.Getenv("LOAD_FIRST"))
+}
+
+func TestLoadFirstNoneExist(t *testing.T) {
+	used, err := LoadFirst(filepath.Join(t.TempDir(), ".env"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", used)
+}
+
+func TestLoadFirstInvalid(t *testing.T) {
+	path := writeFile(t, "NOT A PAIR\n")
+
+	used, err := LoadFirst(path)
+	assert.Error(t, err)
+	assert.Equal(t, path, used)
+}
+
+func TestLoadFileAccumulate(t *testing.T) {
+	Reset()
+	for _, name := range []string{"ORIGIN", "LABELS", "LAST_ORIGIN"} {
+		os.Unsetenv(name)
+		defer os.Unsetenv(name)
+	}
+
+	path := writeFile(t, "ORIGIN=https://a.example.com\nORIGIN=\"b, c\"\nLABELS=a=1\nLABELS=b=2,c=3\nLAST_ORIGIN=x\nLAST_ORIGIN=y\n")
+	assert.NoError(t, LoadFile(path, false))
+
+	origins := StringSlice("ORIGIN", false, nil, ",", "something", Accumulate())
+	labels := Map("LABELS", false, nil, ",", "=", "something", Accumulate())
+	last := StringSlice("LAST_ORIGIN", false, nil, ",", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"https://a.example.com", "b, c"}, *origins)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, *labels)
+	assert.Equal(t, []string{"y"}, *last)
+	assert.Equal(t, "file", Source("ORIGIN"))
+	assert.Panics(t, func() { String("NAME", false, "", "something", Accumulate()) })
+}
@@ -1,12 +1,27 @@
 package env
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
-	"time"
 	"strings"
+	"time"
 )
 
 type envVar struct {
@@ -19,19 +34,39 @@ type envVar struct {
 	setValue     func(interface{}, string) error
 	setDefault   func(interface{}, interface{})
 	envValue     *string
+	secret       bool                                       // Whether the raw value must be kept out of error messages.
+	validators   []func(context.Context, interface{}) error // Checks run against the resolved value.
+	plainChecks  []func(interface{}) error                  // Checks given by Validate, also run against string defaults at registration.
+	formatValue  func(interface{}) string                   // Renders a value as it would be written in the environment, if not fmt.Sprint.
+	trimSpace    *bool                                      // Overrides the registry's SetTrimSpace setting, if not nil.
+	source       *string                                    // Where the last Parse() resolved the value from, or "" if it failed.
+	fallback     string                                     // Another variable to consult when this one is unset.
+	aliases      []string                                   // Deprecated names to consult when this one is unset.
+	requiredIf   []condition                                // Conditions under which the variable is required.
+	ignoreCase   *bool                                      // Set by the IgnoreCase option, if the constructor supports it.
+	defaultUnit  *time.Duration                             // Set by the DefaultUnit option, if the constructor supports it.
+	unescape     *bool                                      // Cleared by the KeepEscapes option, if the constructor supports it.
+	invert       *bool                                      // Set by the Invert option, if the constructor supports it.
+	accumulate   bool                                       // Whether every value of a key repeated in a loaded file is used.
+	joinRepeated func([]string) string                      // Joins the values of a repeated key, if the constructor supports Accumulate.
+	indexed      bool                                       // Whether the value is collected from NAME_0, NAME_1 and so on.
+	allowEmpty   bool                                       // Whether a variable that is set but empty counts as provided.
+	defaultFunc  func() (string, error)                     // Computes the default when the variable is unset, if not nil.
+	onSet        func(interface{})                          // Called with the value pointer after Parse() assigns it, if not nil.
+	base         int                                        // The base IntBase values are written in, or 0 if it is detected from the prefix.
 }
 
-var envs []envVar
+// condition holds when another variable, name, resolves to value.
+type condition struct {
+	name  string
+	value string
+}
 
 // define a help flag
 var help = flag.Bool("help", false, "--help to show help")
 
-func init() {
-	envs = make([]envVar, 0)
-}
-
 // String registers a new string environment variable with the specified parameters.
-// It appends the variable configuration to the global envs slice and returns a pointer
+// It appends the variable configuration to the default registry and returns a pointer
 // to the string value that will be populated when environment variables are parsed.
 //
 // Parameters:
@@ -46,43 +81,49 @@ func init() {
 //
 // The returned pointer will be populated with the environment variable value
 // after calling env.Parse()
-func String(name string, required bool, defaultValue, help string) *string {
+func String(name string, required bool, defaultValue, help string, opts ...Option) *string {
+	return defaultRegistry.String(name, required, defaultValue, help, opts...)
+}
+
+// String is like the package-level String but registers the variable with r.
+func (r *Registry) String(name string, required bool, defaultValue, help string, opts ...Option) *string {
 	// Create a new string pointer to store the variable value.
 	v := new(string)
 
-	// Append a new environment variable definition to `envs`.
-	envs = append(envs, envVar{
-		v,            // Pointer to the string variable.
-		name,         // The name of the environment variable.
-		"string",     // The data type (for documentation/help purposes).
-		required,     // Whether the variable is required.
-		defaultValue, // The default value if the variable is not set.
-		help,         // Help text describing the variable.
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "string",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
 
 		// Function to parse and set the string value from input.
-		func(a interface{}, b string) error {
+		setValue: func(a interface{}, b string) error {
 			*a.(*string) = b // Directly assign the string value.
 			return nil
 		},
 
 		// Function to set the default value if the environment variable is not set.
-		func(a interface{}, b interface{}) {
+		setDefault: func(a interface{}, b interface{}) {
 			*a.(*string) = b.(string) // Assign the default value, ensuring it's a string.
 		},
 
-		new(string), // Pointer to store the raw string representation of the environment variable.
-	})
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
 
 	// Return the pointer to the string variable so it can be accessed elsewhere.
 	return v
 }
 
 // Int defines an integer environment variable with the specified parameters.
-// It appends the variable configuration to the global envs slice and returns a pointer
+// It appends the variable configuration to the default registry and returns a pointer
 // to the integer value that will be populated when environment variables are parsed.
 //
 // The function handles string to integer conversion internally and supports values
-// up to 64-bit integers. If the environment variable contains an invalid integer,
+// that fit in the platform's int, so 32 bits on 32-bit targets and 64 bits otherwise.
+// If the environment variable contains an invalid or out of range integer,
 // Parse() will return an error.
 //
 // Parameters:
@@ -97,22 +138,27 @@ func String(name string, required bool, defaultValue, help string) *string {
 //
 // The returned pointer will be populated with the environment variable value
 // after calling env.Parse()
-func Int(name string, required bool, defaultValue int, help string) *int {
+func Int(name string, required bool, defaultValue int, help string, opts ...Option) *int {
+	return defaultRegistry.Int(name, required, defaultValue, help, opts...)
+}
+
+// Int is like the package-level Int but registers the variable with r.
+func (r *Registry) Int(name string, required bool, defaultValue int, help string, opts ...Option) *int {
 	// Create a new integer pointer to store the variable value.
 	v := new(int)
 
-	// Append a new environment variable definition to `envs`.
-	envs = append(envs, envVar{
-		v,            // Pointer to the integer variable.
-		name,         // The name of the environment variable.
-		"integer",    // The data type (for documentation/help purposes).
-		required,     // Whether the variable is required.
-		defaultValue, // The default value if the variable is not set.
-		help,         // Help text describing the variable.
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the integer variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "integer",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
 
 		// Function to parse and set the integer value from a string.
-		func(a interface{}, b string) error {
-			v, err := strconv.ParseInt(b, 10, 64) // Convert string to int64.
+		setValue: func(a interface{}, b string) error {
+			v, err := strconv.ParseInt(b, 10, strconv.IntSize) // Convert string to a platform sized int, rejecting overflow.
 
 			if err != nil {
 				a = nil // If parsing fails, set `a` to nil.
@@ -124,20 +170,200 @@ func Int(name string, required bool, defaultValue int, help string) *int {
 		},
 
 		// Function to set the default value if the environment variable is not set.
-		func(a interface{}, b interface{}) {
+		setDefault: func(a interface{}, b interface{}) {
 			if val, ok := b.(int); ok { // Ensure `b` is an int before assignment.
 				*a.(*int) = val
 			}
 		},
 
-		new(string), // Pointer to store the raw string representation of the environment variable.
-	})
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the integer variable so it can be accessed elsewhere.
+	return v
+}
+
+// IntBase defines an integer environment variable written in the given base, adds it to the list of expected
+// environment variables, and returns a pointer to its value, i.e. base 8 for PERMISSIONS=0644 or base 16 for a
+// hex mask. A base of 0 detects the base from the prefix as Go literals do: 0x for hex, 0o or 0 for octal and
+// 0b for binary, with decimal otherwise. Values that overflow the platform int cause Parse() to return an
+// error, and a base other than 0 or 2 to 36 causes a panic when the variable is registered.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - base: The base values are written in, or 0 to detect it from the prefix.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	perm := env.IntBase("PERMISSIONS", false, 0o644, 8, "Mode of created files")
+func IntBase(name string, required bool, defaultValue int, base int, help string, opts ...Option) *int {
+	return defaultRegistry.IntBase(name, required, defaultValue, base, help, opts...)
+}
+
+// IntBase is like the package-level IntBase but registers the variable with r.
+func (r *Registry) IntBase(name string, required bool, defaultValue int, base int, help string, opts ...Option) *int {
+	// Check the base up front so that a bad base fails fast.
+	if base != 0 && (base < 2 || base > 36) {
+		panic(fmt.Sprintf("env: invalid base for %s: %d", name, base))
+	}
+
+	// Create a new integer pointer to store the variable value.
+	v := new(int)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the integer variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "integer",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		base:         base,         // The base values are written in.
+
+		// Function to parse and set the integer value from a string in the given base.
+		setValue: func(i interface{}, s string) error {
+			n, err := strconv.ParseInt(s, base, strconv.IntSize) // Convert string to a platform sized int, rejecting overflow.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return fmt.Errorf("base %d: %w", base, err)
+			}
+
+			*i.(*int) = int(n) // Store the parsed value as an int.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int) = i2.(int) // Assign default integer value.
+		},
+
+		// Function to render an integer value in the given base, or in decimal when the base is detected.
+		formatValue: func(i interface{}) string {
+			if base == 0 {
+				return strconv.Itoa(i.(int))
+			}
+			return strconv.FormatInt(int64(i.(int)), base)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
 
 	// Return the pointer to the integer variable so it can be accessed elsewhere.
 	return v
 }
 
-// Float64 defines a float64 environment variable, adds it to the list of expected environment variables (`envs`),
+// Uint defines an unsigned integer environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Negative values cause Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	maxFiles := env.Uint("MAX_OPEN_FILES", false, 1024, "File descriptor limit")
+func Uint(name string, required bool, defaultValue uint, help string, opts ...Option) *uint {
+	return defaultRegistry.Uint(name, required, defaultValue, help, opts...)
+}
+
+// Uint is like the package-level Uint but registers the variable with r.
+func (r *Registry) Uint(name string, required bool, defaultValue uint, help string, opts ...Option) *uint {
+	// Create a new uint pointer to store the variable value.
+	v := new(uint)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the uint variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "uint",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the uint value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseUint(s, 10, strconv.IntSize) // Convert string to a platform sized uint.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*uint) = uint(v) // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*uint) = i2.(uint) // Assign default uint value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the uint variable so it can be accessed elsewhere.
+	return v
+}
+
+// Uint64 defines a uint64 environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Negative values cause Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	maxBytes := env.Uint64("MAX_BYTES", false, 1<<30, "Maximum upload size in bytes")
+func Uint64(name string, required bool, defaultValue uint64, help string, opts ...Option) *uint64 {
+	return defaultRegistry.Uint64(name, required, defaultValue, help, opts...)
+}
+
+// Uint64 is like the package-level Uint64 but registers the variable with r.
+func (r *Registry) Uint64(name string, required bool, defaultValue uint64, help string, opts ...Option) *uint64 {
+	// Create a new uint64 pointer to store the variable value.
+	v := new(uint64)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the uint64 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "uint64",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the uint64 value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseUint(s, 10, 64) // Convert string to uint64.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*uint64) = v // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*uint64) = i2.(uint64) // Assign default uint64 value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the uint64 variable so it can be accessed elsewhere.
+	return v
+}
+
+// Float64 defines a float64 environment variable, adds it to the list of expected environment variables,
 // and returns a pointer to its value.
 //
 // Parameters:
@@ -149,21 +375,26 @@ func Int(name string, required bool, defaultValue int, help string) *int {
 // Example:
 //
 //	timeout := env.Float64("TIMEOUT", false, 30.0, "Request timeout in seconds")
-func Float64(name string, required bool, defaultValue float64, help string) *float64 {
+func Float64(name string, required bool, defaultValue float64, help string, opts ...Option) *float64 {
+	return defaultRegistry.Float64(name, required, defaultValue, help, opts...)
+}
+
+// Float64 is like the package-level Float64 but registers the variable with r.
+func (r *Registry) Float64(name string, required bool, defaultValue float64, help string, opts ...Option) *float64 {
 	// Create a new float64 pointer to store the variable value.
 	v := new(float64)
 
-	// Append a new environment variable definition to `envs`.
-	envs = append(envs, envVar{
-		v,            // Pointer to the float64 variable.
-		name,         // The name of the environment variable.
-		"float",      // The data type (for documentation/help purposes).
-		required,     // Whether the variable is required.
-		defaultValue, // The default value if the variable is not set.
-		help,         // Help text describing the variable.
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the float64 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "float",      // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
 
 		// Function to parse and set the float64 value from a string.
-		func(i interface{}, s string) error {
+		setValue: func(i interface{}, s string) error {
 			v, err := strconv.ParseFloat(s, 64) // Convert string to float64.
 			if err != nil {
 				i = nil // If parsing fails, set `i` to nil.
@@ -175,174 +406,2778 @@ func Float64(name string, required bool, defaultValue float64, help string) *flo
 		},
 
 		// Function to set the default value if the environment variable is not set.
-		func(i1, i2 interface{}) {
+		setDefault: func(i1, i2 interface{}) {
 			*i1.(*float64) = i2.(float64) // Assign default float64 value.
 		},
 
-		new(string), // Pointer to store the raw string representation of the environment variable.
-	})
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
 
 	// Return the pointer to the float64 variable so it can be accessed elsewhere.
 	return v
 }
 
-// Bool defines a boolean environment variable, adds it to the list of expected environment variables (`envs`),
+// Int64 defines an int64 environment variable, adds it to the list of expected environment variables,
 // and returns a pointer to its value.
 //
 // Parameters:
 //   - name: Environment variable name.
 //   - required: Whether the variable is mandatory.
-//   - defaultValue: Default value if the variable is not set.
-//   - help: Description of the variable for documentation.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
 //
 // Example:
 //
-//	debugMode := env.Bool("DEBUG_MODE", false, false, "Enable debug mode")
-func Bool(name string, required bool, defaultValue bool, help string) *bool {
-	// Create a new boolean pointer to store the variable value.
-	v := new(bool)
+//	maxID := env.Int64("MAX_ID", false, 1<<40, "Largest ID to process")
+func Int64(name string, required bool, defaultValue int64, help string, opts ...Option) *int64 {
+	return defaultRegistry.Int64(name, required, defaultValue, help, opts...)
+}
 
-	// Append a new environment variable definition to `envs`.
-	envs = append(envs, envVar{
-		v,            // Pointer to the boolean variable.
-		name,         // The name of the environment variable.
-		"boolean",    // The data type (for documentation/help purposes).
-		required,     // Whether the variable is required.
-		defaultValue, // The default value if the variable is not set.
-		help,         // Help text describing the variable.
+// Int64 is like the package-level Int64 but registers the variable with r.
+func (r *Registry) Int64(name string, required bool, defaultValue int64, help string, opts ...Option) *int64 {
+	// Create a new int64 pointer to store the variable value.
+	v := new(int64)
 
-		// Function to parse and set the boolean value from a string.
-		func(i interface{}, s string) error {
-			v, err := strconv.ParseBool(s) // Convert string to boolean.
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the int64 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "int64",      // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the int64 value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseInt(s, 10, 64) // Convert string to int64.
 			if err != nil {
 				i = nil // If parsing fails, set `i` to nil.
 				return err
 			}
 
-			*i.(*bool) = bool(v) // Store the parsed value.
+			*i.(*int64) = v // Store the parsed value.
 			return nil
 		},
 
 		// Function to set the default value if the environment variable is not set.
-		func(i1, i2 interface{}) {
-			*i1.(*bool) = i2.(bool) // Assign default boolean value.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int64) = i2.(int64) // Assign default int64 value.
 		},
 
-		new(string), // Pointer to store the raw string representation of the environment variable.
-	})
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
 
-	// Return the pointer to the boolean variable so it can be accessed elsewhere.
+	// Return the pointer to the int64 variable so it can be accessed elsewhere.
 	return v
 }
 
-func Duration(name string, required bool, defaultValue time.Duration, help string) *time.Duration {
-	v := new(time.Duration)
+// Int32 defines an int32 environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values that do not fit in 32 bits cause Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	workers := env.Int32("WORKERS", false, 4, "Number of worker goroutines")
+func Int32(name string, required bool, defaultValue int32, help string, opts ...Option) *int32 {
+	return defaultRegistry.Int32(name, required, defaultValue, help, opts...)
+}
 
-	envs = append(envs, envVar{
-		v,
-		name,
-		"duration",
-		required,
-		defaultValue,
-		help,
-		func(i interface{}, s string) error {
-			v, err := time.ParseDuration(s)
+// Int32 is like the package-level Int32 but registers the variable with r.
+func (r *Registry) Int32(name string, required bool, defaultValue int32, help string, opts ...Option) *int32 {
+	// Create a new int32 pointer to store the variable value.
+	v := new(int32)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the int32 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "int32",      // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the int32 value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseInt(s, 10, 32) // Convert string to int32, rejecting overflow.
 			if err != nil {
-				i = nil
+				i = nil // If parsing fails, set `i` to nil.
 				return err
 			}
 
-			*i.(*time.Duration) = v
+			*i.(*int32) = int32(v) // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int32) = i2.(int32) // Assign default int32 value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the int32 variable so it can be accessed elsewhere.
+	return v
+}
+
+// Float32 defines a float32 environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values outside the float32 range cause Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	ratio := env.Float32("SAMPLE_RATIO", false, 0.5, "Fraction of requests to sample")
+func Float32(name string, required bool, defaultValue float32, help string, opts ...Option) *float32 {
+	return defaultRegistry.Float32(name, required, defaultValue, help, opts...)
+}
+
+// Float32 is like the package-level Float32 but registers the variable with r.
+func (r *Registry) Float32(name string, required bool, defaultValue float32, help string, opts ...Option) *float32 {
+	// Create a new float32 pointer to store the variable value.
+	v := new(float32)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the float32 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "float32",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the float32 value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseFloat(s, 32) // Convert string to float32, rejecting overflow.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
 
+			*i.(*float32) = float32(v) // Store the narrowed value.
 			return nil
 		},
-		func(i1, i2 interface{}) {
-			*i1.(*time.Duration) = i2.(time.Duration)
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*float32) = i2.(float32) // Assign default float32 value.
 		},
-		new(string),
-	})
 
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the float32 variable so it can be accessed elsewhere.
 	return v
 }
 
-// Parse processes command-line flags and environment variables.
-func Parse() error {
-	// Parse the main flags package to enable the --help function.
-	flag.Parse()
+// Bool defines a boolean environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if the variable is not set.
+//   - help: Description of the variable for documentation.
+//
+// Example:
+//
+//	debugMode := env.Bool("DEBUG_MODE", false, false, "Enable debug mode")
+func Bool(name string, required bool, defaultValue bool, help string, opts ...Option) *bool {
+	return defaultRegistry.Bool(name, required, defaultValue, help, opts...)
+}
 
-	// If the --help flag is provided, print the help message and exit.
-	if *help {
-		fmt.Println("Config values are set using environment variables. For more info please see the following list.")
-		fmt.Println("")
-		fmt.Println(Help())
+// Bool is like the package-level Bool but registers the variable with r.
+func (r *Registry) Bool(name string, required bool, defaultValue bool, help string, opts ...Option) *bool {
+	// Create a new boolean pointer to store the variable value, and a flag the Invert option can set.
+	v := new(bool)
+	invert := new(bool)
 
-		// Exit the program after displaying help.
-		os.Exit(0)
-	}
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the boolean variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "boolean",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		invert:       invert,       // Whether the stored value is negated.
 
-	// Collect errors encountered while processing environment variables.
-	errors := make([]string, 0)
+		// Function to parse and set the boolean value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := strconv.ParseBool(s) // Convert string to boolean.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
 
-	// Iterate through all expected environment variables.
-	for _, e := range envs {
-		err := processEnvVar(e)
-		if err != nil {
-			// Append an error message if the environment variable is invalid or missing.
-			errors = append(errors, fmt.Sprintf("expected: %s type: %s got: %s", e.name, e.varType, *e.envValue))
-		}
-	}
+			*i.(*bool) = v != *invert // Store the parsed value, negated if inverted.
+			return nil
+		},
 
-	// If there were any errors, format and return them as a single error message.
-	if len(errors) > 0 {
-		errString := strings.Join(errors, "\n")
-		return fmt.Errorf("%s", errString)
-	}
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*bool) = i2.(bool) != *invert // Assign default boolean value, negated if inverted.
+		},
 
-	// Return nil if all environment variables were processed successfully.
-	return nil
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the boolean variable so it can be accessed elsewhere.
+	return v
 }
 
-// processEnvVar retrieves and validates a single environment variable.
-func processEnvVar(e envVar) error {
-	// Get the environment variable value from the system.
-	*e.envValue = os.Getenv(e.name)
+// FlexBool defines a boolean environment variable like Bool, but also accepts the tokens operators tend to
+// write by hand: yes/no, on/off and enabled/disabled, in any case, alongside everything strconv.ParseBool
+// accepts. Any other value causes Parse() to return an error listing the accepted tokens.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if the variable is not set.
+//   - help: Description of the variable for documentation.
+//
+// Example:
+//
+//	metrics := env.FlexBool("METRICS", false, true, "Expose Prometheus metrics")
+func FlexBool(name string, required bool, defaultValue bool, help string, opts ...Option) *bool {
+	return defaultRegistry.FlexBool(name, required, defaultValue, help, opts...)
+}
 
-	// If the variable is empty and it's not required, set its default value.
-	if *e.envValue == "" && !e.required {
-		e.setDefault(e.value, e.defaultValue)
-		return nil
-	}
+// FlexBool is like the package-level FlexBool but registers the variable with r.
+func (r *Registry) FlexBool(name string, required bool, defaultValue bool, help string, opts ...Option) *bool {
+	// Create a new boolean pointer to store the variable value, and a flag the Invert option can set.
+	v := new(bool)
+	invert := new(bool)
 
-	// If the variable is empty but required, return an error.
-	if *e.envValue == "" && e.required {
-		return fmt.Errorf("%s should be provided", e.name)
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the boolean variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "boolean",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		invert:       invert,       // Whether the stored value is negated.
+
+		// Function to parse and set the boolean value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := parseFlexBool(s) // Convert string to boolean, accepting the extra tokens.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*bool) = v != *invert // Store the parsed value, negated if inverted.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*bool) = i2.(bool) != *invert // Assign default boolean value, negated if inverted.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the boolean variable so it can be accessed elsewhere.
+	return v
+}
+
+// parseFlexBool parses s as a boolean, accepting yes/no, on/off and enabled/disabled in any case as well as
+// the values accepted by strconv.ParseBool.
+func parseFlexBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "on", "enabled":
+		return true, nil
+	case "no", "off", "disabled":
+		return false, nil
 	}
 
-	// Try setting the value using a method that processes it.
-	err := e.setValue(e.value, *e.envValue)
+	v, err := strconv.ParseBool(strings.ToLower(s))
 	if err != nil {
-		return err
+		return false, errors.New("must be one of [true, false, 1, 0, t, f, yes, no, on, off, enabled, disabled]")
 	}
 
-	// Return nil if everything is successful.
-	return nil
+	return v, nil
 }
 
-// Help generates and returns a help message listing all environment variables.
-func Help() string {
-	// Initialize the help message with a title.
-	h := make([]string, 1)
-	h[0] = "Environment variables:"
+// Duration defines a time.Duration environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values are parsed with time.ParseDuration, so "1500ms" or "2h30m" are accepted.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	timeout := env.Duration("TIMEOUT", false, 30*time.Second, "Request timeout")
+func Duration(name string, required bool, defaultValue time.Duration, help string, opts ...Option) *time.Duration {
+	return defaultRegistry.Duration(name, required, defaultValue, help, opts...)
+}
 
-	// Iterate through all environment variables to generate their descriptions.
-	for _, e := range envs {
-		def := fmt.Sprintf("'%v'", e.defaultValue)
-		if def == "''" {
-			def = "no default"
-		}
+// Duration is like the package-level Duration but registers the variable with r.
+func (r *Registry) Duration(name string, required bool, defaultValue time.Duration, help string, opts ...Option) *time.Duration {
+	// Create a new duration pointer to store the variable value, and a unit the DefaultUnit option can set.
+	v := new(time.Duration)
+	unit := new(time.Duration)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the duration variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "duration",   // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		defaultUnit:  unit,         // The unit of values without a suffix, or 0 to reject them.
+
+		// Function to parse and set the duration value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := time.ParseDuration(s) // Convert string to time.Duration.
+			if n, nerr := strconv.ParseFloat(s, 64); err != nil && nerr == nil && *unit != 0 {
+				v, err = unitDuration(s, n, *unit) // Interpret a bare number in the default unit.
+			}
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*time.Duration) = v // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*time.Duration) = i2.(time.Duration) // Assign default duration value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the duration variable so it can be accessed elsewhere.
+	return v
+}
+
+// unitDuration returns n, parsed from s, as a number of unit, or an error if it is not finite or does not fit
+// in a time.Duration.
+func unitDuration(s string, n float64, unit time.Duration) (time.Duration, error) {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	d := n * float64(unit)
+	if d >= math.MaxInt64 || d < math.MinInt64 {
+		return 0, fmt.Errorf("duration %q out of range", s)
+	}
+
+	return time.Duration(d), nil
+}
+
+// Time defines a time.Time environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values are parsed with time.Parse using the supplied layout, which
+// defaults to time.RFC3339 when empty.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - layout: Layout passed to time.Parse, i.e. time.RFC3339.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	releaseAt := env.Time("RELEASE_AT", true, time.Time{}, time.RFC3339, "Release cutoff timestamp")
+func Time(name string, required bool, defaultValue time.Time, layout, help string, opts ...Option) *time.Time {
+	return defaultRegistry.Time(name, required, defaultValue, layout, help, opts...)
+}
+
+// Time is like the package-level Time but registers the variable with r.
+func (r *Registry) Time(name string, required bool, defaultValue time.Time, layout, help string, opts ...Option) *time.Time {
+	// Fall back to RFC3339 if no layout was supplied.
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	// Create a new time pointer to store the variable value.
+	v := new(time.Time)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the time variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "time",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the time value from a string.
+		setValue: func(i interface{}, s string) error {
+			v, err := time.Parse(layout, s) // Convert string to time.Time using the layout.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return fmt.Errorf("expected layout %q: %w", layout, err)
+			}
+
+			*i.(*time.Time) = v // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*time.Time) = i2.(time.Time) // Assign default time value.
+		},
+
+		// Function to render a time value using the layout.
+		formatValue: func(i interface{}) string {
+			return i.(time.Time).Format(layout)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the time variable so it can be accessed elsewhere.
+	return v
+}
+
+// URL defines a url.URL environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values must be absolute URLs with both a scheme and a host.
+//
+// The default value is parsed when the variable is registered, and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default URL if not set, or "" for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	apiBase := env.URL("API_BASE", false, "https://api.example.com/v1", "Upstream API base URL")
+func URL(name string, required bool, defaultValue string, help string, opts ...Option) *url.URL {
+	return defaultRegistry.URL(name, required, defaultValue, help, opts...)
+}
+
+// URL is like the package-level URL but registers the variable with r.
+func (r *Registry) URL(name string, required bool, defaultValue string, help string, opts ...Option) *url.URL {
+	// Parse the default up front so that a bad default fails fast.
+	def := new(url.URL)
+	if defaultValue != "" {
+		u, err := parseURL(defaultValue)
+		if err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+		def = u
+	}
+
+	// Create a new URL pointer to store the variable value.
+	v := new(url.URL)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the URL variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "url",        // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the URL value from a string.
+		setValue: func(i interface{}, s string) error {
+			u, err := parseURL(s) // Convert string to an absolute URL.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*url.URL) = *u // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*url.URL) = *def // Assign the pre-parsed default URL.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the URL variable so it can be accessed elsewhere.
+	return v
+}
+
+// parseURL parses s and ensures it has both a scheme and a host.
+func parseURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q must include a scheme and host", s)
+	}
+
+	return u, nil
+}
+
+// IP defines a net.IP environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Both IPv4 and IPv6 addresses are accepted.
+//
+// The default value is parsed when the variable is registered, and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default address if not set, or "" for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	bindIP := env.IP("BIND_IP", false, "127.0.0.1", "Address to bind the listener to")
+func IP(name string, required bool, defaultValue string, help string, opts ...Option) *net.IP {
+	return defaultRegistry.IP(name, required, defaultValue, help, opts...)
+}
+
+// IP is like the package-level IP but registers the variable with r.
+func (r *Registry) IP(name string, required bool, defaultValue string, help string, opts ...Option) *net.IP {
+	// Parse the default up front so that a bad default fails fast.
+	var def net.IP
+	if defaultValue != "" {
+		ip, err := parseIP(defaultValue)
+		if err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+		def = ip
+	}
+
+	// Create a new IP pointer to store the variable value.
+	v := new(net.IP)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the IP variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "ip",         // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the IP value from a string.
+		setValue: func(i interface{}, s string) error {
+			ip, err := parseIP(s) // Convert string to an IP address.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*net.IP) = ip // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*net.IP) = slices.Clone(def) // Assign a copy of the pre-parsed default IP.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the IP variable so it can be accessed elsewhere.
+	return v
+}
+
+// parseIP parses s as an IPv4 or IPv6 address.
+func parseIP(s string) (net.IP, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not a valid IP address", s)
+	}
+
+	return ip, nil
+}
+
+// CIDR defines a net.IPNet environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values use CIDR notation, i.e. "10.0.0.0/8", and the stored network
+// is the masked network rather than the literal address.
+//
+// The default value is parsed when the variable is registered, and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default network if not set, or "" for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	trusted := env.CIDR("TRUSTED_NET", false, "10.0.0.0/8", "Network allowed to call admin endpoints")
+func CIDR(name string, required bool, defaultValue string, help string, opts ...Option) *net.IPNet {
+	return defaultRegistry.CIDR(name, required, defaultValue, help, opts...)
+}
+
+// CIDR is like the package-level CIDR but registers the variable with r.
+func (r *Registry) CIDR(name string, required bool, defaultValue string, help string, opts ...Option) *net.IPNet {
+	// Parse the default up front so that a bad default fails fast.
+	def := new(net.IPNet)
+	if defaultValue != "" {
+		_, n, err := net.ParseCIDR(defaultValue)
+		if err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+		def = n
+	}
+
+	// Create a new IPNet pointer to store the variable value.
+	v := new(net.IPNet)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the IPNet variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "cidr",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the network value from a string.
+		setValue: func(i interface{}, s string) error {
+			_, n, err := net.ParseCIDR(s) // Convert string to the masked network.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*net.IPNet) = *n // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*net.IPNet) = net.IPNet{IP: slices.Clone(def.IP), Mask: slices.Clone(def.Mask)} // Assign a copy of the pre-parsed default network.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the IPNet variable so it can be accessed elsewhere.
+	return v
+}
+
+// Port defines an integer environment variable holding a TCP or UDP port, adds it to the list of expected
+// environment variables, and returns a pointer to its value. Values outside 1-65535 cause Parse() to return
+// an error. Port 0, which asks the operating system for an ephemeral port, is rejected as well, since it is
+// rarely intended in configuration; use Int for variables where it is meaningful.
+//
+// A defaultValue of 0 means no default, leaving the value at 0 when the variable is unset. Any other default
+// outside 1-65535 causes a panic when the variable is registered.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default port if not set, or 0 for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	port := env.Port("BIND_PORT", false, 8080, "Port to listen on")
+func Port(name string, required bool, defaultValue int, help string, opts ...Option) *int {
+	return defaultRegistry.Port(name, required, defaultValue, help, opts...)
+}
+
+// Port is like the package-level Port but registers the variable with r.
+func (r *Registry) Port(name string, required bool, defaultValue int, help string, opts ...Option) *int {
+	// Check the default up front so that a bad default fails fast.
+	if defaultValue != 0 {
+		if err := checkPort(defaultValue); err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+	}
+
+	// Create a new integer pointer to store the variable value.
+	v := new(int)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the port variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "port",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse, check and set the port value from a string.
+		setValue: func(i interface{}, s string) error {
+			n, err := strconv.Atoi(s) // Convert string to an int.
+			if err == nil {
+				err = checkPort(n)
+			}
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*int) = n // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int) = i2.(int) // Assign the default port.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the port variable so it can be accessed elsewhere.
+	return v
+}
+
+// checkPort returns an error if n is not a valid, non-ephemeral port number.
+func checkPort(n int) error {
+	if n < 1 || n > 65535 {
+		return fmt.Errorf("port %d out of range 1-65535", n)
+	}
+
+	return nil
+}
+
+// TLSVersion defines a TLS protocol version environment variable, adds it to the list of expected environment
+// variables, and returns a pointer to its value, one of the crypto/tls version constants such as
+// tls.VersionTLS12, ready for tls.Config's MinVersion or MaxVersion. The accepted values are 1.0, 1.1, 1.2 and
+// 1.3, and anything else causes Parse() to return an error listing them.
+//
+// A defaultValue of 0 means no default, leaving the value at 0 when the variable is unset, which crypto/tls
+// treats as its own default. Any other default that is not a TLS version constant causes a panic when the
+// variable is registered.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default version if not set, i.e. tls.VersionTLS12, or 0 for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	minVersion := env.TLSVersion("TLS_MIN_VERSION", false, tls.VersionTLS12, "Minimum TLS version")
+func TLSVersion(name string, required bool, defaultValue uint16, help string, opts ...Option) *uint16 {
+	return defaultRegistry.TLSVersion(name, required, defaultValue, help, opts...)
+}
+
+// TLSVersion is like the package-level TLSVersion but registers the variable with r.
+func (r *Registry) TLSVersion(name string, required bool, defaultValue uint16, help string, opts ...Option) *uint16 {
+	// Check the default up front so that a bad default fails fast.
+	if defaultValue != 0 && tlsVersionName(defaultValue) == "" {
+		panic(fmt.Sprintf("env: invalid default value for %s: unknown TLS version 0x%04x", name, defaultValue))
+	}
+
+	// Create a new uint16 pointer to store the variable value.
+	v := new(uint16)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,             // Pointer to the version variable.
+		name:         name,          // The name of the environment variable.
+		varType:      "tls version", // The data type (for documentation/help purposes).
+		required:     required,      // Whether the variable is required.
+		defaultValue: defaultValue,  // The default value if the variable is not set.
+		help:         help,          // Help text describing the variable.
+
+		// Function to look up and set the version constant from a string.
+		setValue: func(i interface{}, s string) error {
+			for _, tv := range tlsVersions {
+				if tv.name == s {
+					*i.(*uint16) = tv.version // Store the matching constant.
+					return nil
+				}
+			}
+
+			i = nil // If parsing fails, set `i` to nil.
+			return fmt.Errorf("unknown TLS version %q, must be one of [1.0, 1.1, 1.2, 1.3]", s)
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*uint16) = i2.(uint16) // Assign the default version.
+		},
+
+		// Function to render a version constant as it would be written in the environment.
+		formatValue: func(i interface{}) string {
+			return tlsVersionName(i.(uint16))
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the version variable so it can be accessed elsewhere.
+	return v
+}
+
+// tlsVersions lists the values accepted by TLSVersion and the crypto/tls constants they map to.
+var tlsVersions = []struct {
+	name    string
+	version uint16
+}{
+	{"1.0", tls.VersionTLS10},
+	{"1.1", tls.VersionTLS11},
+	{"1.2", tls.VersionTLS12},
+	{"1.3", tls.VersionTLS13},
+}
+
+// tlsVersionName returns the value TLSVersion accepts for the constant version, or "" if there is none.
+func tlsVersionName(version uint16) string {
+	for _, tv := range tlsVersions {
+		if tv.version == version {
+			return tv.name
+		}
+	}
+
+	return ""
+}
+
+// LogLevel defines a log level environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value as a slog.Level. The accepted values are debug, info, warn and error, in
+// any case, and anything else causes Parse() to return an error listing them.
+//
+// An empty defaultValue leaves the value at slog.LevelInfo, the zero slog.Level, when the variable is unset.
+// Any other default that is not an accepted value causes a panic when the variable is registered.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default level if not set, i.e. "info".
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	level := env.LogLevel("LOG_LEVEL", false, "info", "Minimum level of log messages")
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: *level}))
+func LogLevel(name string, required bool, defaultValue string, help string, opts ...Option) *slog.Level {
+	return defaultRegistry.LogLevel(name, required, defaultValue, help, opts...)
+}
+
+// LogLevel is like the package-level LogLevel but registers the variable with r.
+func (r *Registry) LogLevel(name string, required bool, defaultValue string, help string, opts ...Option) *slog.Level {
+	// Parse the default up front so that a bad default fails fast.
+	var def slog.Level
+	if defaultValue != "" {
+		var err error
+		if def, err = parseLogLevel(defaultValue); err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+	}
+
+	// Create a new slog.Level pointer to store the variable value.
+	v := new(slog.Level)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,           // Pointer to the level variable.
+		name:         name,        // The name of the environment variable.
+		varType:      "log level", // The data type (for documentation/help purposes).
+		required:     required,    // Whether the variable is required.
+		defaultValue: def,         // The parsed default value if the variable is not set.
+		help:         help,        // Help text describing the variable.
+
+		// Function to parse and set the level value from a string.
+		setValue: func(i interface{}, s string) error {
+			l, err := parseLogLevel(s) // Convert string to a slog.Level.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*slog.Level) = l // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*slog.Level) = i2.(slog.Level) // Assign the default level.
+		},
+
+		// Function to render a level as it would be written in the environment.
+		formatValue: func(i interface{}) string {
+			return strings.ToLower(i.(slog.Level).String())
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the level variable so it can be accessed elsewhere.
+	return v
+}
+
+// parseLogLevel parses s, one of debug, info, warn or error in any case, as a slog.Level.
+func parseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+
+	return 0, fmt.Errorf("unknown log level %q, must be one of [debug, info, warn, error]", s)
+}
+
+// FilePath defines a string environment variable holding a file path, adds it to the list of expected
+// environment variables, and returns a pointer to the path. If mustExist is true, Parse() returns an error
+// unless the resolved path, whether from the environment or the default, names a readable file rather than
+// a directory. An empty path is not checked.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default path if not set.
+//   - mustExist: Whether the path must name an existing, readable file.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	cert := env.FilePath("TLS_CERT_FILE", true, "", true, "Path to the TLS certificate")
+func FilePath(name string, required bool, defaultValue string, mustExist bool, help string, opts ...Option) *string {
+	return defaultRegistry.FilePath(name, required, defaultValue, mustExist, help, opts...)
+}
+
+// FilePath is like the package-level FilePath but registers the variable with r.
+func (r *Registry) FilePath(name string, required bool, defaultValue string, mustExist bool, help string, opts ...Option) *string {
+	// Check the resolved path like any other validator, so that defaults are checked too.
+	validators := make([]func(context.Context, interface{}) error, 0)
+	if mustExist {
+		validators = append(validators, func(_ context.Context, i interface{}) error {
+			return checkFile(i.(string))
+		})
+	}
+
+	// Create a new string pointer to store the variable value.
+	v := new(string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the path variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "path",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		validators:   validators,   // Checks run against the resolved path.
+
+		// Function to set the path value from a string.
+		setValue: func(i interface{}, s string) error {
+			*i.(*string) = s // Store the path as is.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign the default path.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the path variable so it can be accessed elsewhere.
+	return v
+}
+
+// checkFile returns an error if path is not empty and does not name a readable file.
+func checkFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+
+	return nil
+}
+
+// StringSlice defines a []string environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values are split on delimiter, which defaults to the one set by
+// SetDefaultDelimiter when empty. Surrounding whitespace is trimmed from each element and empty elements are
+// dropped.
+//
+// An element wrapped in double quotes may contain the delimiter, surrounding whitespace and \" or \\ escapes,
+// so that `"a,b",c` holds the elements "a,b" and "c". A quoted element is kept even if it is empty, and an
+// unterminated quote causes Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - delimiter: Separator between elements, i.e. ",".
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	origins := env.StringSlice("ALLOWED_ORIGINS", false, []string{"localhost"}, ",", "CORS allowed origins")
+func StringSlice(name string, required bool, defaultValue []string, delimiter, help string, opts ...Option) *[]string {
+	return defaultRegistry.StringSlice(name, required, defaultValue, delimiter, help, opts...)
+}
+
+// StringSlice is like the package-level StringSlice but registers the variable with r.
+func (r *Registry) StringSlice(name string, required bool, defaultValue []string, delimiter, help string, opts ...Option) *[]string {
+	// Fall back to the default delimiter if none was supplied.
+	delimiter = r.listDelimiter(delimiter)
+
+	// Create a new string slice pointer to store the variable value.
+	v := new([]string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string slice variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "[]string",   // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to split and set the string slice value from a string.
+		setValue: func(i interface{}, s string) error {
+			parts, err := splitQuoted(s, delimiter)
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*[]string) = parts // Store the trimmed, non-empty elements.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*[]string) = slices.Clone(i2.([]string)) // Assign a copy of the default string slice value.
+		},
+
+		// Function to render a string slice value joined by the delimiter, quoting elements as needed.
+		formatValue: func(i interface{}) string {
+			parts := make([]string, 0)
+			for _, p := range i.([]string) {
+				parts = append(parts, quoteElement(p, delimiter))
+			}
+			return strings.Join(parts, delimiter)
+		},
+
+		// Function to join the values of a repeated key, keeping each as a single element.
+		joinRepeated: func(values []string) string {
+			parts := make([]string, 0, len(values))
+			for _, p := range values {
+				parts = append(parts, quoteElement(p, delimiter))
+			}
+			return strings.Join(parts, delimiter)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the string slice variable so it can be accessed elsewhere.
+	return v
+}
+
+// IndexedStringSlice defines a string slice environment variable that is collected from numbered variables, such
+// as ARG_0, ARG_1 and ARG_2 for the name ARG, as some platforms express lists. It adds the variable to the list
+// of expected environment variables and returns a pointer to its value. Parse() reads NAME_0, NAME_1 and so on in
+// numeric order and stops at the first index that is unset or empty, so the indices must be contiguous from 0:
+// with ARG_0 and ARG_2 set but not ARG_1, only ARG_0 is used, and without ARG_0 the variable counts as unset.
+// Each element is used as is, including surrounding whitespace and commas. The default is an empty slice.
+//
+// Parameters:
+//   - name: Prefix of the numbered environment variables, without the trailing underscore.
+//   - required: Whether at least NAME_0 must be set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	args := env.IndexedStringSlice("ARG", false, "Extra command-line arguments")
+func IndexedStringSlice(name string, required bool, help string, opts ...Option) *[]string {
+	return defaultRegistry.IndexedStringSlice(name, required, help, opts...)
+}
+
+// IndexedStringSlice is like the package-level IndexedStringSlice but registers the variable with r.
+func (r *Registry) IndexedStringSlice(name string, required bool, help string, opts ...Option) *[]string {
+	// Mark the variable as indexed, so the numbered variables are joined into a single raw value when it is looked up.
+	indexed := func(e *envVar) {
+		e.indexed = true
+	}
+
+	return r.StringSlice(name, required, nil, ",", help, append([]Option{indexed}, opts...)...)
+}
+
+// StringSliceUnique is like StringSlice but removes duplicate elements, keeping the first occurrence of each
+// in order, i.e. for allowlists. Elements are compared case-sensitively unless the IgnoreCase option is given,
+// in which case the spelling of the first occurrence is kept. Duplicates in the default value are removed too.
+//
+// Example:
+//
+//	allowed := env.StringSliceUnique("ALLOWED_USERS", false, nil, ",", "Users allowed to sign in", env.IgnoreCase())
+func StringSliceUnique(name string, required bool, defaultValue []string, delimiter, help string, opts ...Option) *[]string {
+	return defaultRegistry.StringSliceUnique(name, required, defaultValue, delimiter, help, opts...)
+}
+
+// StringSliceUnique is like the package-level StringSliceUnique but registers the variable with r.
+func (r *Registry) StringSliceUnique(name string, required bool, defaultValue []string, delimiter, help string, opts ...Option) *[]string {
+	// Fall back to the default delimiter if none was supplied.
+	delimiter = r.listDelimiter(delimiter)
+
+	// Create a new string slice pointer to store the variable value, and a flag the IgnoreCase option can set.
+	v := new([]string)
+	ignoreCase := new(bool)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string slice variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "[]string",   // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		ignoreCase:   ignoreCase,   // Whether duplicates are found regardless of case.
+
+		// Function to split, dedupe and set the string slice value from a string.
+		setValue: func(i interface{}, s string) error {
+			parts, err := splitQuoted(s, delimiter)
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*[]string) = unique(parts, *ignoreCase) // Store the first occurrence of each element.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*[]string) = unique(i2.([]string), *ignoreCase) // Assign a deduped copy of the default value.
+		},
+
+		// Function to render a string slice value joined by the delimiter, quoting elements as needed.
+		formatValue: func(i interface{}) string {
+			parts := make([]string, 0)
+			for _, p := range i.([]string) {
+				parts = append(parts, quoteElement(p, delimiter))
+			}
+			return strings.Join(parts, delimiter)
+		},
+
+		// Function to join the values of a repeated key, keeping each as a single element.
+		joinRepeated: func(values []string) string {
+			parts := make([]string, 0, len(values))
+			for _, p := range values {
+				parts = append(parts, quoteElement(p, delimiter))
+			}
+			return strings.Join(parts, delimiter)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the string slice variable so it can be accessed elsewhere.
+	return v
+}
+
+// unique returns a copy of parts without duplicates, keeping the first occurrence of each element.
+func unique(parts []string, ignoreCase bool) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		key := p
+		if ignoreCase {
+			key = strings.ToLower(p)
+		}
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// IntSlice defines a []int environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Values are split on delimiter, which defaults to the one set by
+// SetDefaultDelimiter when empty, and each trimmed element is converted with strconv.Atoi. Any element that is
+// not an integer causes Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - delimiter: Separator between elements, i.e. ",".
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	backoffs := env.IntSlice("BACKOFFS", false, []int{100, 200, 400}, ",", "Retry backoff steps in milliseconds")
+func IntSlice(name string, required bool, defaultValue []int, delimiter, help string, opts ...Option) *[]int {
+	return defaultRegistry.IntSlice(name, required, defaultValue, delimiter, help, opts...)
+}
+
+// IntSlice is like the package-level IntSlice but registers the variable with r.
+func (r *Registry) IntSlice(name string, required bool, defaultValue []int, delimiter, help string, opts ...Option) *[]int {
+	// Fall back to the default delimiter if none was supplied.
+	delimiter = r.listDelimiter(delimiter)
+
+	// Create a new int slice pointer to store the variable value.
+	v := new([]int)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the int slice variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "[]integer",  // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to split, parse and set the int slice value from a string.
+		setValue: func(i interface{}, s string) error {
+			parts := splitList(s, delimiter)
+			ints := make([]int, 0, len(parts))
+			for _, p := range parts {
+				n, err := strconv.Atoi(p) // Convert each element to an int.
+				if err != nil {
+					i = nil // If parsing fails, set `i` to nil.
+					return fmt.Errorf("invalid element %q: %w", p, err)
+				}
+				ints = append(ints, n)
+			}
+
+			*i.(*[]int) = ints // Store the parsed values.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*[]int) = slices.Clone(i2.([]int)) // Assign a copy of the default int slice value.
+		},
+
+		// Function to render an int slice value joined by the delimiter.
+		formatValue: func(i interface{}) string {
+			parts := make([]string, 0)
+			for _, n := range i.([]int) {
+				parts = append(parts, strconv.Itoa(n))
+			}
+			return strings.Join(parts, delimiter)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the int slice variable so it can be accessed elsewhere.
+	return v
+}
+
+// Map defines a map[string]string environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to its value. Entries are separated by pairSep (default set by SetDefaultDelimiter) and
+// keys are separated from values by kvSep (default "="). An entry without kvSep causes Parse() to return an error,
+// and later duplicate keys overwrite earlier ones.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - pairSep: Separator between entries, i.e. ",".
+//   - kvSep: Separator between a key and its value, i.e. "=".
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	labels := env.Map("LABELS", false, nil, ",", "=", "Labels attached to emitted metrics")
+func Map(name string, required bool, defaultValue map[string]string, pairSep, kvSep, help string, opts ...Option) *map[string]string {
+	return defaultRegistry.Map(name, required, defaultValue, pairSep, kvSep, help, opts...)
+}
+
+// Map is like the package-level Map but registers the variable with r.
+func (r *Registry) Map(name string, required bool, defaultValue map[string]string, pairSep, kvSep, help string, opts ...Option) *map[string]string {
+	return r.MapMulti(name, required, defaultValue, []string{pairSep}, kvSep, help, opts...)
+}
+
+// MapMulti is like Map but accepts any of pairSeps between entries, i.e. both "," and ";" for labels pasted
+// from different sources. Values are rendered with the first separator. Since every separator splits entries,
+// keys and values cannot contain any of them, and where one separator contains another, i.e. ";" and ";;",
+// the longest match is used. An empty pairSeps uses the separator set by SetDefaultDelimiter, and a separator
+// equal to kvSep causes a panic when the variable is registered.
+//
+// Example:
+//
+//	labels := env.MapMulti("LABELS", false, nil, []string{",", ";"}, "=", "Labels attached to emitted metrics")
+func MapMulti(name string, required bool, defaultValue map[string]string, pairSeps []string, kvSep, help string, opts ...Option) *map[string]string {
+	return defaultRegistry.MapMulti(name, required, defaultValue, pairSeps, kvSep, help, opts...)
+}
+
+// MapMulti is like the package-level MapMulti but registers the variable with r.
+func (r *Registry) MapMulti(name string, required bool, defaultValue map[string]string, pairSeps []string, kvSep, help string, opts ...Option) *map[string]string {
+	// Fall back to "=" if no key/value separator was supplied.
+	if kvSep == "" {
+		kvSep = "="
+	}
+
+	// Fall back to the default delimiter for missing entry separators, trying longer separators first.
+	seps := make([]string, 0, len(pairSeps))
+	for _, sep := range pairSeps {
+		seps = append(seps, r.listDelimiter(sep))
+	}
+	if len(seps) == 0 {
+		seps = append(seps, r.listDelimiter(""))
+	}
+	if slices.Contains(seps, kvSep) {
+		panic(fmt.Sprintf("env: ambiguous separators for %s: %q separates both entries and keys from values", name, kvSep))
+	}
+	pairSep := seps[0]
+	slices.SortStableFunc(seps, func(a, b string) int { return len(b) - len(a) })
+
+	// Create a new map pointer to store the variable value.
+	v := new(map[string]string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,                   // Pointer to the map variable.
+		name:         name,                // The name of the environment variable.
+		varType:      "map[string]string", // The data type (for documentation/help purposes).
+		required:     required,            // Whether the variable is required.
+		defaultValue: defaultValue,        // The default value if the variable is not set.
+		help:         help,                // Help text describing the variable.
+
+		// Function to split, parse and set the map value from a string.
+		setValue: func(i interface{}, s string) error {
+			m := make(map[string]string)
+			for _, p := range splitAny(s, seps) {
+				k, val, ok := strings.Cut(p, kvSep) // Separate the key from its value.
+				if !ok {
+					i = nil // If parsing fails, set `i` to nil.
+					return fmt.Errorf("invalid entry %q: missing %q", p, kvSep)
+				}
+				m[strings.TrimSpace(k)] = strings.TrimSpace(val)
+			}
+
+			*i.(*map[string]string) = m // Store the parsed entries.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*map[string]string) = maps.Clone(i2.(map[string]string)) // Assign a copy of the default map value.
+		},
+
+		// Function to render a map value as sorted key/value pairs.
+		formatValue: func(i interface{}) string {
+			pairs := make([]string, 0)
+			for k, val := range i.(map[string]string) {
+				pairs = append(pairs, k+kvSep+val)
+			}
+			slices.Sort(pairs)
+			return strings.Join(pairs, pairSep)
+		},
+
+		// Function to join the values of a repeated key, each holding one or more entries.
+		joinRepeated: func(values []string) string {
+			return strings.Join(values, pairSep)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the map variable so it can be accessed elsewhere.
+	return v
+}
+
+// SetDefaultDelimiter sets the separator used by StringSlice, IntSlice and Map when they are called with an
+// empty delimiter, initially ",". A delimiter passed to the constructor always takes precedence. The default
+// is resolved when a variable is registered, so it must be set before the variables that rely on it.
+func SetDefaultDelimiter(sep string) {
+	defaultRegistry.SetDefaultDelimiter(sep)
+}
+
+// SetDefaultDelimiter is like the package-level SetDefaultDelimiter but applies to the variables registered with r.
+func (r *Registry) SetDefaultDelimiter(sep string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaultDelimiter = sep
+}
+
+// listDelimiter returns delimiter, or the default delimiter of r if delimiter is empty.
+func (r *Registry) listDelimiter(delimiter string) string {
+	if delimiter != "" {
+		return delimiter
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.defaultDelimiter == "" {
+		return ","
+	}
+
+	return r.defaultDelimiter
+}
+
+// splitList splits s on delimiter, trimming whitespace
+// around each element and dropping elements that are empty.
+func splitList(s, delimiter string) []string {
+	parts := make([]string, 0)
+	for _, p := range strings.Split(s, delimiter) {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	return parts
+}
+
+// splitQuoted splits s on delimiter like splitList, except that elements wrapped in double quotes are
+// taken as is, apart from \" and \\ escapes, and may contain the delimiter.
+func splitQuoted(s, delimiter string) ([]string, error) {
+	parts := make([]string, 0)
+	for s != "" {
+		// Split off unquoted elements as splitList would.
+		rest := strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(rest, `"`) {
+			p, next, _ := strings.Cut(s, delimiter)
+			if p = strings.TrimSpace(p); p != "" {
+				parts = append(parts, p)
+			}
+			s = next
+			continue
+		}
+
+		// Read a quoted element up to its closing quote.
+		var b strings.Builder
+		closed := false
+		i := 1
+		for ; i < len(rest) && !closed; i++ {
+			switch {
+			case rest[i] == '\\' && i+1 < len(rest) && (rest[i+1] == '"' || rest[i+1] == '\\'):
+				i++
+				b.WriteByte(rest[i])
+			case rest[i] == '"':
+				closed = true
+			default:
+				b.WriteByte(rest[i])
+			}
+		}
+		if !closed {
+			return nil, fmt.Errorf("unterminated quote in %q", rest)
+		}
+		parts = append(parts, b.String())
+
+		// Only whitespace may separate the closing quote from the next delimiter.
+		after, next, found := strings.Cut(rest[i:], delimiter)
+		if strings.TrimSpace(after) != "" {
+			return nil, fmt.Errorf("unexpected %q after quoted element %q", strings.TrimSpace(after), b.String())
+		}
+		if !found {
+			break
+		}
+		s = next
+	}
+
+	return parts, nil
+}
+
+// quoteElement returns p quoted for splitQuoted if it would otherwise not survive being split on delimiter.
+func quoteElement(p, delimiter string) string {
+	if p != "" && p == strings.TrimSpace(p) && !strings.Contains(p, delimiter) && !strings.HasPrefix(p, `"`) {
+		return p
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+}
+
+// splitAny splits s on any of seps, trying them in order at each position, trimming whitespace
+// around each element and dropping elements that are empty.
+func splitAny(s string, seps []string) []string {
+	parts := make([]string, 0)
+	add := func(p string) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	start := 0
+	for i := 0; i < len(s); i++ {
+		for _, sep := range seps {
+			if strings.HasPrefix(s[i:], sep) {
+				add(s[start:i])
+				i += len(sep) - 1
+				start = i + 1
+				break
+			}
+		}
+	}
+	add(s[start:])
+
+	return parts
+}
+
+// Bytes defines a []byte environment variable holding standard base64-encoded data, adds it to the list of
+// expected environment variables, and returns a pointer to the decoded value. The variable is treated
+// as a secret, so its raw value is never included in Parse() errors.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	signingKey := env.Bytes("SIGNING_KEY", true, nil, "Base64-encoded token signing key")
+func Bytes(name string, required bool, defaultValue []byte, help string, opts ...Option) *[]byte {
+	return defaultRegistry.Bytes(name, required, defaultValue, help, opts...)
+}
+
+// Bytes is like the package-level Bytes but registers the variable with r.
+func (r *Registry) Bytes(name string, required bool, defaultValue []byte, help string, opts ...Option) *[]byte {
+	// Create a new byte slice pointer to store the variable value.
+	v := new([]byte)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the byte slice variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "base64",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to decode and set the byte slice value from a string.
+		setValue: func(i interface{}, s string) error {
+			b, err := base64.StdEncoding.DecodeString(s) // Decode the standard base64 string.
+			if err != nil {
+				i = nil // If decoding fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*[]byte) = b // Store the decoded value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*[]byte) = slices.Clone(i2.([]byte)) // Assign a copy of the default byte slice value.
+		},
+
+		// Function to render a byte slice value as standard base64.
+		formatValue: func(i interface{}) string {
+			return base64.StdEncoding.EncodeToString(i.([]byte))
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+		secret:   true,        // Never echo the encoded secret.
+	}, opts)
+
+	// Return the pointer to the byte slice variable so it can be accessed elsewhere.
+	return v
+}
+
+// byteUnits maps the upper-cased size suffixes accepted by ByteSize to their multipliers.
+// Decimal suffixes (KB, MB, ...) are powers of 1000 and binary suffixes (KiB, MiB, ...) are powers of 1024.
+var byteUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ByteSize defines an int64 environment variable holding a human-readable size, adds it to the list of expected
+// environment variables, and returns a pointer to the size in bytes.
+//
+// Values are a whole number optionally followed by a case-insensitive unit. KB, MB, GB and TB are decimal
+// (KB is 1000 bytes) while KiB, MiB, GiB and TiB are binary (KiB is 1024 bytes). A bare number is treated as bytes.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default size in bytes if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	maxBody := env.ByteSize("MAX_BODY", false, 10*1000*1000, "Maximum request body size, i.e. 10MB")
+func ByteSize(name string, required bool, defaultValue int64, help string, opts ...Option) *int64 {
+	return defaultRegistry.ByteSize(name, required, defaultValue, help, opts...)
+}
+
+// ByteSize is like the package-level ByteSize but registers the variable with r.
+func (r *Registry) ByteSize(name string, required bool, defaultValue int64, help string, opts ...Option) *int64 {
+	// Create a new int64 pointer to store the variable value.
+	v := new(int64)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the int64 variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "bytesize",   // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the size in bytes from a string.
+		setValue: func(i interface{}, s string) error {
+			n, err := parseByteSize(s) // Convert the human-readable size to bytes.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*int64) = n // Store the parsed value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int64) = i2.(int64) // Assign default int64 value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the int64 variable so it can be accessed elsewhere.
+	return v
+}
+
+// parseByteSize converts a size such as "10MB" or "512KiB" into a number of bytes.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+
+	// Split the leading digits from the unit suffix.
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, fmt.Errorf("%q does not start with a number", s)
+	}
+
+	n, err := strconv.ParseInt(s[:end], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	unit := strings.ToUpper(strings.TrimSpace(s[end:]))
+	mult, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q", s[end:])
+	}
+
+	if n > math.MaxInt64/mult {
+		return 0, fmt.Errorf("%q overflows int64", s)
+	}
+
+	return n * mult, nil
+}
+
+// Enum defines a string environment variable restricted to a fixed set of values, adds it to the list of expected
+// environment variables, and returns a pointer to its value. Parse() returns an error listing the
+// permitted values if the variable holds anything outside allowed.
+//
+// The default value of a variable that is not required is checked against allowed when the variable is
+// registered, and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set, or "" for none.
+//   - allowed: The permitted values.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	logLevel := env.Enum("LOG_LEVEL", false, "info", []string{"debug", "info", "warn", "error"}, "Log verbosity")
+func Enum(name string, required bool, defaultValue string, allowed []string, help string, opts ...Option) *string {
+	return defaultRegistry.Enum(name, required, defaultValue, allowed, help, opts...)
+}
+
+// Enum is like the package-level Enum but registers the variable with r.
+func (r *Registry) Enum(name string, required bool, defaultValue string, allowed []string, help string, opts ...Option) *string {
+	// Create a new string pointer to store the variable value.
+	v := new(string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "enum",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to validate and set the string value.
+		setValue: func(i interface{}, s string) error {
+			if err := checkEnum(s, allowed); err != nil {
+				i = nil // If validation fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*string) = s // Store the validated value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign default string value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the string variable so it can be accessed elsewhere.
+	return v
+}
+
+// Choices is like Enum, but also returns a pointer to the index of the value in allowed, so that behaviour can be
+// switched on the choice without looking it up again. Parse() updates the index alongside the value, and it is
+// -1 while the value is empty.
+//
+// Example:
+//
+//	mode, modeIndex := env.Choices("SYNC_MODE", false, "fast", []string{"fast", "safe", "paranoid"}, "Write durability")
+func Choices(name string, required bool, defaultValue string, allowed []string, help string, opts ...Option) (*string, *int) {
+	return defaultRegistry.Choices(name, required, defaultValue, allowed, help, opts...)
+}
+
+// Choices is like the package-level Choices but registers the variable with r.
+func (r *Registry) Choices(name string, required bool, defaultValue string, allowed []string, help string, opts ...Option) (*string, *int) {
+	// Create a new string pointer to store the variable value, and an int pointer for its index.
+	v := new(string)
+	idx := new(int)
+	*idx = -1
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "enum",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to validate and set the string value and its index.
+		setValue: func(i interface{}, s string) error {
+			if err := checkEnum(s, allowed); err != nil {
+				i = nil // If validation fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*string) = s // Store the validated value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign default string value.
+		},
+
+		// Function to keep the index in step with the value, unless this is a copy resolved by Check().
+		onSet: func(i interface{}) {
+			if i == v {
+				*idx = slices.Index(allowed, *i.(*string)) // Store its position, or -1 if there is none.
+			}
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointers to the string variable and its index so they can be accessed elsewhere.
+	return v, idx
+}
+
+// checkEnum returns an error listing the allowed values if s is not one of them.
+func checkEnum(s string, allowed []string) error {
+	if !slices.Contains(allowed, s) {
+		return fmt.Errorf("must be one of [%s]", strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
+
+// StringMatch defines a string environment variable whose value must fully match pattern, adds it to the list
+// of expected environment variables, and returns a pointer to its value. Parse() returns an error naming the
+// pattern if the variable holds anything else.
+//
+// The pattern is compiled when the variable is registered, and the default value of a variable that is not
+// required is checked against it. An invalid pattern or default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set, or "" for none.
+//   - pattern: Regular expression, in RE2 syntax, that the whole value must match.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	slug := env.StringMatch("TENANT_SLUG", true, "", `[a-z0-9-]+`, "Lowercase tenant identifier")
+func StringMatch(name string, required bool, defaultValue, pattern, help string, opts ...Option) *string {
+	return defaultRegistry.StringMatch(name, required, defaultValue, pattern, help, opts...)
+}
+
+// StringMatch is like the package-level StringMatch but registers the variable with r.
+func (r *Registry) StringMatch(name string, required bool, defaultValue, pattern, help string, opts ...Option) *string {
+	// Compile the pattern up front so that a bad pattern fails fast.
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		panic(fmt.Sprintf("env: invalid pattern for %s: %v", name, err))
+	}
+
+	// Create a new string pointer to store the variable value.
+	v := new(string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "string",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to validate and set the string value.
+		setValue: func(i interface{}, s string) error {
+			if err := checkMatch(s, re, pattern); err != nil {
+				i = nil // If validation fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*string) = s // Store the validated value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign default string value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the string variable so it can be accessed elsewhere.
+	return v
+}
+
+// checkMatch returns an error naming pattern if s does not match re, its anchored form.
+func checkMatch(s string, re *regexp.Regexp, pattern string) error {
+	if !re.MatchString(s) {
+		return fmt.Errorf("must match %s", pattern)
+	}
+
+	return nil
+}
+
+// OneOfInt defines an integer environment variable restricted to a fixed set of values, adds it to the list of
+// expected environment variables, and returns a pointer to its value. Parse() returns an error listing the
+// permitted values if the variable holds anything outside allowed.
+//
+// Unless the variable is required, the default value is checked against allowed when the variable is registered,
+// and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - allowed: The permitted values.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	verbosity := env.OneOfInt("VERBOSITY", false, 1, []int{0, 1, 2, 3}, "Log verbosity")
+func OneOfInt(name string, required bool, defaultValue int, allowed []int, help string, opts ...Option) *int {
+	return defaultRegistry.OneOfInt(name, required, defaultValue, allowed, help, opts...)
+}
+
+// OneOfInt is like the package-level OneOfInt but registers the variable with r.
+func (r *Registry) OneOfInt(name string, required bool, defaultValue int, allowed []int, help string, opts ...Option) *int {
+	// Validate the default up front so that a bad default fails fast. A required variable never uses it.
+	if !required {
+		if err := checkOneOfInt(defaultValue, allowed); err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+	}
+
+	// Create a new integer pointer to store the variable value.
+	v := new(int)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the integer variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "integer",    // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse, validate and set the integer value from a string.
+		setValue: func(i interface{}, s string) error {
+			n, err := strconv.ParseInt(s, 10, strconv.IntSize) // Convert string to a platform sized int.
+			if err == nil {
+				err = checkOneOfInt(int(n), allowed)
+			}
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*int) = int(n) // Store the validated value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*int) = i2.(int) // Assign the default integer value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the integer variable so it can be accessed elsewhere.
+	return v
+}
+
+// checkOneOfInt returns an error listing the allowed values if n is not one of them.
+func checkOneOfInt(n int, allowed []int) error {
+	if !slices.Contains(allowed, n) {
+		parts := make([]string, 0, len(allowed))
+		for _, a := range allowed {
+			parts = append(parts, strconv.Itoa(a))
+		}
+		return fmt.Errorf("must be one of [%s]", strings.Join(parts, ", "))
+	}
+
+	return nil
+}
+
+// MultiLine defines a string environment variable whose value may span lines, adds it to the list of expected
+// environment variables, and returns a pointer to its value. Literal \n and \r\n sequences in the value are
+// turned into newlines, so that a PEM certificate can be stored in a single-line variable. The KeepEscapes
+// option disables this, and the default value is used as is.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	cert := env.MultiLine("TLS_CERT", true, "", "PEM encoded TLS certificate", env.Secret())
+func MultiLine(name string, required bool, defaultValue string, help string, opts ...Option) *string {
+	return defaultRegistry.MultiLine(name, required, defaultValue, help, opts...)
+}
+
+// MultiLine is like the package-level MultiLine but registers the variable with r.
+func (r *Registry) MultiLine(name string, required bool, defaultValue string, help string, opts ...Option) *string {
+	// Create a new string pointer to store the variable value, and a flag the KeepEscapes option can clear.
+	v := new(string)
+	unescape := new(bool)
+	*unescape = true
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the string variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "string",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		unescape:     unescape,     // Whether escaped newlines are unescaped.
+
+		// Function to unescape newlines and set the string value.
+		setValue: func(i interface{}, s string) error {
+			if *unescape {
+				s = strings.NewReplacer(`\r\n`, "\n", `\n`, "\n").Replace(s) // Turn escaped newlines into real ones.
+			}
+
+			*i.(*string) = s // Store the value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign default string value.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the string variable so it can be accessed elsewhere.
+	return v
+}
+
+// Email defines a string environment variable holding an email address, adds it to the list of expected
+// environment variables, and returns a pointer to its value. Values are parsed with net/mail.ParseAddress,
+// so either a bare address or one with a display name, i.e. "Alerts <alerts@example.com>", is accepted, and
+// the bare address is stored. An invalid address causes Parse() to return an error.
+//
+// The default value is parsed when the variable is registered, and an invalid default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default address if not set, or "" for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	alertEmail := env.Email("ALERT_EMAIL", true, "", "Address that receives alerts")
+func Email(name string, required bool, defaultValue string, help string, opts ...Option) *string {
+	return defaultRegistry.Email(name, required, defaultValue, help, opts...)
+}
+
+// Email is like the package-level Email but registers the variable with r.
+func (r *Registry) Email(name string, required bool, defaultValue string, help string, opts ...Option) *string {
+	// Parse the default up front so that a bad default fails fast.
+	def := ""
+	if defaultValue != "" {
+		addr, err := mail.ParseAddress(defaultValue)
+		if err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+		}
+		def = addr.Address
+	}
+
+	// Create a new string pointer to store the variable value.
+	v := new(string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the address variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "email",      // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to parse and set the address value from a string.
+		setValue: func(i interface{}, s string) error {
+			addr, err := mail.ParseAddress(s) // Parse the address, with or without a display name.
+			if err != nil {
+				i = nil // If parsing fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*string) = addr.Address // Store the bare address.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = def // Assign the pre-parsed default address.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the address variable so it can be accessed elsewhere.
+	return v
+}
+
+// hostnameTimeout bounds how long Parse() waits for a Hostname variable to resolve.
+const hostnameTimeout = 5 * time.Second
+
+// Hostname defines a string environment variable holding a host name, adds it to the list of expected
+// environment variables, and returns a pointer to its value. If resolve is true, Parse() looks the resolved
+// host up with the default resolver and returns an error if it does not resolve within five seconds, or before
+// the context given to ParseContext is done, so that an unreachable dependency is reported at startup.
+// Otherwise the value is stored as is. An empty host is not looked up.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default host if not set.
+//   - resolve: Whether the host must resolve during Parse().
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	dbHost := env.Hostname("DB_HOST", false, "localhost", true, "Database host")
+func Hostname(name string, required bool, defaultValue string, resolve bool, help string, opts ...Option) *string {
+	return defaultRegistry.Hostname(name, required, defaultValue, resolve, help, opts...)
+}
+
+// Hostname is like the package-level Hostname but registers the variable with r.
+func (r *Registry) Hostname(name string, required bool, defaultValue string, resolve bool, help string, opts ...Option) *string {
+	// Resolve the host like any other validator, so that defaults are checked too.
+	validators := make([]func(context.Context, interface{}) error, 0)
+	if resolve {
+		validators = append(validators, func(ctx context.Context, i interface{}) error {
+			return resolveHost(ctx, i.(string))
+		})
+	}
+
+	// Create a new string pointer to store the variable value.
+	v := new(string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the host variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "hostname",   // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+		validators:   validators,   // Checks run against the resolved host.
+
+		// Function to set the host value from a string.
+		setValue: func(i interface{}, s string) error {
+			*i.(*string) = s // Store the host as is.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*string) = i2.(string) // Assign the default host.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the host variable so it can be accessed elsewhere.
+	return v
+}
+
+// resolveHost returns an error if host is not empty and cannot be resolved within hostnameTimeout,
+// or before ctx is done.
+func resolveHost(ctx context.Context, host string) error {
+	if host == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hostnameTimeout)
+	defer cancel()
+
+	_, err := net.DefaultResolver.LookupHost(ctx, host)
+	return err
+}
+
+// Glob defines an environment variable holding a file path pattern, adds it to the list of expected environment
+// variables, and returns a pointer to the paths it matches, i.e. CONFIG_FILES=/etc/app/*.yaml. Parse() expands
+// the pattern with filepath.Glob, so the paths are sorted. A malformed pattern causes Parse() to return an error,
+// as does a pattern that matches nothing if the variable is required.
+//
+// The default pattern of a variable that is not required is checked when the variable is registered, and a
+// malformed default causes a panic.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory, and its pattern must match at least one path.
+//   - defaultValue: Default pattern if not set, or "" for none.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	configFiles := env.Glob("CONFIG_FILES", false, "/etc/app/*.yaml", "Configuration files to load")
+func Glob(name string, required bool, defaultValue string, help string, opts ...Option) *[]string {
+	return defaultRegistry.Glob(name, required, defaultValue, help, opts...)
+}
+
+// Glob is like the package-level Glob but registers the variable with r.
+func (r *Registry) Glob(name string, required bool, defaultValue string, help string, opts ...Option) *[]string {
+	// Create a new string slice pointer to store the matched paths.
+	v := new([]string)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the paths variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "glob",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to expand the pattern and set the matched paths.
+		setValue: func(i interface{}, s string) error {
+			paths, err := filepath.Glob(s)
+			if err == nil && len(paths) == 0 && required {
+				err = fmt.Errorf("pattern %q matches no files", s)
+			}
+			if err != nil {
+				i = nil // If expansion fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*[]string) = paths // Store the matched paths.
+			return nil
+		},
+
+		// Function to expand the default pattern if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			paths := make([]string, 0)
+			if i2.(string) != "" {
+				paths, _ = filepath.Glob(i2.(string)) // The pattern was checked at registration.
+			}
+			*i1.(*[]string) = paths
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the paths variable so it can be accessed elsewhere.
+	return v
+}
+
+// Regexp defines a regular expression environment variable, adds it to the list of expected environment variables,
+// and returns a pointer to the compiled expression. Patterns that fail to compile cause Parse() to return
+// an error.
+//
+// The default pattern is compiled when the variable is registered, and an invalid default causes a panic. An empty
+// default compiles to the empty pattern, which matches every string.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default pattern if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	pathFilter := env.Regexp("PATH_FILTER", false, "^/api/", "Only log requests matching this pattern")
+func Regexp(name string, required bool, defaultValue string, help string, opts ...Option) *regexp.Regexp {
+	return defaultRegistry.Regexp(name, required, defaultValue, help, opts...)
+}
+
+// Regexp is like the package-level Regexp but registers the variable with r.
+func (r *Registry) Regexp(name string, required bool, defaultValue string, help string, opts ...Option) *regexp.Regexp {
+	// Compile the default up front so that a bad default fails fast.
+	def, err := regexp.Compile(defaultValue)
+	if err != nil {
+		panic(fmt.Sprintf("env: invalid default value for %s: %v", name, err))
+	}
+
+	// Create a new regexp pointer to store the variable value.
+	v := new(regexp.Regexp)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the regexp variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "regexp",     // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to compile and set the regexp value from a string.
+		setValue: func(i interface{}, s string) error {
+			re, err := regexp.Compile(s) // Compile the pattern.
+			if err != nil {
+				i = nil // If compiling fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*regexp.Regexp) = *re // Store the compiled expression.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			*i1.(*regexp.Regexp) = *def // Assign the pre-compiled default expression.
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the regexp variable so it can be accessed elsewhere.
+	return v
+}
+
+// JSON defines an environment variable holding a JSON document, adds it to the list of expected environment
+// variables, and returns a pointer to the value unmarshaled into T with encoding/json. Documents that
+// cannot be unmarshaled into T cause Parse() to return an error.
+//
+// Parameters:
+//   - name: Environment variable name.
+//   - required: Whether the variable is mandatory.
+//   - defaultValue: Default value if not set.
+//   - help: Description for documentation.
+//
+// Example:
+//
+//	flags := env.JSON("FEATURE_FLAGS", false, map[string]bool{}, "Feature flags as a JSON object")
+func JSON[T any](name string, required bool, defaultValue T, help string, opts ...Option) *T {
+	return JSONIn(defaultRegistry, name, required, defaultValue, help, opts...)
+}
+
+// JSONIn is like JSON but registers the variable with r. It is a function rather than a
+// Registry method because methods cannot have type parameters.
+func JSONIn[T any](r *Registry, name string, required bool, defaultValue T, help string, opts ...Option) *T {
+	// Create a new T pointer to store the variable value.
+	v := new(T)
+
+	// Register a new environment variable definition with `r`.
+	r.register(envVar{
+		value:        v,            // Pointer to the T variable.
+		name:         name,         // The name of the environment variable.
+		varType:      "json",       // The data type (for documentation/help purposes).
+		required:     required,     // Whether the variable is required.
+		defaultValue: defaultValue, // The default value if the variable is not set.
+		help:         help,         // Help text describing the variable.
+
+		// Function to unmarshal and set the T value from a string.
+		setValue: func(i interface{}, s string) error {
+			var t T
+			if err := json.Unmarshal([]byte(s), &t); err != nil {
+				i = nil // If unmarshaling fails, set `i` to nil.
+				return err
+			}
+
+			*i.(*T) = t // Store the unmarshaled value.
+			return nil
+		},
+
+		// Function to set the default value if the environment variable is not set.
+		setDefault: func(i1, i2 interface{}) {
+			// A nil default of an interface type does not hold a T, so assign the zero value instead.
+			if i2 == nil {
+				var zero T
+				*i1.(*T) = zero
+				return
+			}
+
+			*i1.(*T) = deepCopy(i2).(T) // Assign a copy of the default T value.
+		},
+
+		// Function to render a T value as JSON.
+		formatValue: func(i interface{}) string {
+			b, _ := json.Marshal(i)
+			return string(b)
+		},
+
+		envValue: new(string), // Pointer to store the raw string representation of the environment variable.
+	}, opts)
+
+	// Return the pointer to the T variable so it can be accessed elsewhere.
+	return v
+}
+
+// SetPrefix sets a prefix that is prepended verbatim to every registered name when Parse() looks it up
+// in the environment, and when names are shown in help output. Registration code does not change.
+//
+// The prefix only affects subsequent calls to Parse(); values that were already parsed are left as they are.
+//
+// Example:
+//
+//	env.SetPrefix("SVC_A_")
+//	port := env.Int("BIND_PORT", false, 9090, "bind port") // read from SVC_A_BIND_PORT
+func SetPrefix(p string) {
+	defaultRegistry.SetPrefix(p)
+}
+
+// SetPrefix is like the package-level SetPrefix but applies to the variables registered with r.
+func (r *Registry) SetPrefix(p string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefix = p
+	r.prefixVar = ""
+}
+
+// SetPrefixFromEnv makes Parse() read the prefix from the environment variable varName each time it runs,
+// before resolving any other variable, for deployments where the prefix itself is dynamic. varName is looked
+// up as is, without any prefix, and if it is unset or empty no prefix is used. Once a Parse() has run, help
+// output shows names with the prefix it read. It replaces any prefix set by SetPrefix, and a later SetPrefix
+// replaces it in turn.
+//
+// Example:
+//
+//	env.SetPrefixFromEnv("CONFIG_PREFIX")
+//	port := env.Int("BIND_PORT", false, 9090, "bind port") // read from TENANT_A_BIND_PORT if CONFIG_PREFIX=TENANT_A_
+func SetPrefixFromEnv(varName string) {
+	defaultRegistry.SetPrefixFromEnv(varName)
+}
+
+// SetPrefixFromEnv is like the package-level SetPrefixFromEnv but applies to the variables registered with r.
+func (r *Registry) SetPrefixFromEnv(varName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefix = ""
+	r.prefixVar = varName
+}
+
+// SetHook sets a function that Parse() calls after resolving each variable, i.e. to log how the
+// configuration was resolved. fromEnv reports whether the value came from the environment, or another
+// source reported by Source such as a file, rather than the default, and rawValue is the unconverted
+// value, which is "****" for secrets. Variables that fail to parse are reported in the error from Parse()
+// instead. A nil hook, the default, disables this. The hook runs while the registry is locked and must
+// not call back into the package.
+func SetHook(hook func(name, varType string, fromEnv bool, rawValue string)) {
+	defaultRegistry.SetHook(hook)
+}
+
+// SetHook is like the package-level SetHook but applies to the variables registered with r.
+func (r *Registry) SetHook(hook func(name, varType string, fromEnv bool, rawValue string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hook = hook
+}
+
+// SetLogger sets the function the package writes its diagnostic messages to, such as the warnings of
+// DeprecatedAlias, or a NAME_FILE that is ignored because NAME is set as well. No diagnostics are written
+// anywhere else, and a nil logger, the default, discards them, so the package is quiet unless asked. The
+// logger runs while the registry is locked and must not call back into the package.
+//
+// Example:
+//
+//	env.SetLogger(log.Printf)
+func SetLogger(logger func(format string, args ...interface{})) {
+	defaultRegistry.SetLogger(logger)
+}
+
+// SetLogger is like the package-level SetLogger but applies to the variables registered with r.
+func (r *Registry) SetLogger(logger func(format string, args ...interface{})) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logger = logger
+}
+
+// logf writes a diagnostic message to the logger of r, if it has one.
+func (r *Registry) logf(format string, args ...interface{}) {
+	if r.logger != nil {
+		r.logger(format, args...)
+	}
+}
+
+// Reset clears every registered variable, every setting such as SetPrefix, and the --help flag, returning
+// the package to its initial state. It is intended for tests only, so that each test can register a
+// fresh set of variables; pointers returned by earlier registrations are no longer updated by Parse().
+func Reset() {
+	defaultRegistry.reset()
+	*help = false
+}
+
+// envName returns the name used to look e up in the environment, including any prefix.
+func (r *Registry) envName(e envVar) string {
+	return r.prefix + e.name
+}
+
+// Parse processes command-line flags and environment variables. It is equivalent to ParseContext
+// with context.Background().
+//
+// Parse may be called more than once. Every call re-reads the environment and resolves each variable
+// from scratch, so repeated calls with the same environment yield the same values. Defaults are
+// copied, so modifying a returned value, such as a slice, map or JSON default, does not change the
+// default used by later calls. A variable that fails to convert or validate keeps the value it had
+// before the call.
+func Parse() error {
+	return ParseContext(context.Background())
+}
+
+// ParseContext is like Parse but passes ctx to the validators of each variable, such as those given by
+// ValidateContext or the lookup done by Hostname, so that slow validation can be bounded. If ctx is done
+// before every variable has been processed, ParseContext stops and returns the error of ctx, which can be
+// tested with errors.Is, i.e. errors.Is(err, context.DeadlineExceeded).
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := env.ParseContext(ctx)
+func ParseContext(ctx context.Context) error {
+	// Parse the main flags package to enable the --help function.
+	flag.Parse()
+
+	// If the --help flag is provided, print the usage table and exit.
+	if *help {
+		fmt.Println("Config values are set using environment variables. For more info please see the following list.")
+		fmt.Println("")
+		Usage(os.Stdout)
+
+		// Exit the program after displaying help.
+		os.Exit(0)
+	}
+
+	return defaultRegistry.ParseContext(ctx)
+}
+
+// Parse processes the environment variables registered with r, returning an error for every
+// variable that is missing or invalid. Unlike the package-level Parse, it does not parse
+// command-line flags or handle --help, so it is safe to call from library code.
+func (r *Registry) Parse() error {
+	return r.ParseContext(context.Background())
+}
+
+// ParseContext is like Registry.Parse but passes ctx to the validators, as the package-level ParseContext does.
+func (r *Registry) ParseContext(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.processEnvVars(ctx)
+}
+
+// Check resolves and converts every registered variable as Parse() does, and returns the same combined error,
+// but without assigning to the pointers returned by the constructors, so that a configuration can be linted
+// before it is rolled out, i.e. in a --check-config subcommand. It does not parse command-line flags, call the
+// hook set by SetHook, or change what Source reports. It is not named Validate because that is the option that
+// adds a validator.
+//
+// Example:
+//
+//	if err := env.Check(); err != nil {
+//		log.Fatalf("invalid configuration: %v", err)
+//	}
+func Check() error {
+	return defaultRegistry.Check()
+}
+
+// Check is like the package-level Check but resolves the variables registered with r.
+func (r *Registry) Check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Resolve copies of the variables with fresh pointers, so the pointers returned to the caller are untouched.
+	dry := NewRegistry()
+	dry.settings = r.settings
+	dry.hook = nil
+	for _, e := range r.envs {
+		dry.envs = append(dry.envs, e.clone())
+	}
+
+	return dry.processEnvVars(context.Background())
+}
+
+// ParseFrom is like Parse but reads variables through lookup instead of the process environment, i.e. from
+// a map in a test. lookup has the same contract as os.LookupEnv. Command-line flags are not parsed.
+//
+// Example:
+//
+//	vars := map[string]string{"PORT": "8080"}
+//	err := env.ParseFrom(func(name string) (string, bool) {
+//		v, ok := vars[name]
+//		return v, ok
+//	})
+func ParseFrom(lookup func(string) (string, bool)) error {
+	return defaultRegistry.ParseFrom(lookup)
+}
+
+// ParseFrom is like the package-level ParseFrom but processes the variables registered with r.
+func (r *Registry) ParseFrom(lookup func(string) (string, bool)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookupEnv = lookup
+	defer func() { r.lookupEnv = nil }()
+
+	return r.processEnvVars(context.Background())
+}
+
+// MustParse calls Parse() and panics if it returns an error. The panic value is an error
+// wrapping the combined error from Parse(), so every problem is included in the message.
+// It is intended for short-lived tools that should abort immediately on bad configuration.
+func MustParse() {
+	if err := Parse(); err != nil {
+		panic(fmt.Errorf("env: %w", err))
+	}
+}
+
+// processEnvVars processes every variable registered with r and joins any errors, one per line.
+// The result is nil if every variable was processed successfully, or the error of ctx if it is done first.
+func (r *Registry) processEnvVars(ctx context.Context) error {
+	// Read the prefix first if it comes from the environment, since every other name depends on it.
+	if r.prefixVar != "" {
+		r.prefix = r.getenv(r.prefixVar)
+	}
+
+	// Collect errors encountered while processing environment variables.
+	errs := make([]error, 0)
+
+	// Iterate through every expected environment variable, so that all problems are reported at once.
+	for _, e := range r.envs {
+		err := r.processEnvVar(ctx, e)
+
+		// Stop as soon as ctx is done, since later validators would fail for the same reason.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			// Render the error with the custom formatter, if any.
+			var pe *ParseError
+			if errors.As(err, &pe) {
+				pe.formatter = r.errorFormatter
+			}
+
+			// Append the ParseError if the environment variable is invalid or missing.
+			errs = append(errs, err)
+		}
+	}
+
+	// Check the constraints on combinations of variables.
+	errs = append(errs, r.checkGroups()...)
+
+	// Report any unregistered variables if strict mode is enabled.
+	if err := r.checkUnknown(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Combine any errors into a single error.
+	return errors.Join(errs...)
+}
+
+// processEnvVar retrieves and validates a single environment variable.
+// Any error returned is a *ParseError.
+func (r *Registry) processEnvVar(ctx context.Context, e envVar) error {
+	// Forget where any previous Parse() resolved the value from.
+	*e.source = ""
+
+	// Get the environment variable value from the system.
+	v, source, from, err := r.lookup(e)
+	*e.envValue = v
+	if err != nil {
+		return newParseError(e, r.envName(e), LookupFailed, err)
+	}
+
+	// Warn when the value came from a deprecated alias rather than the variable itself, or passed over a NAME_FILE.
+	if v != "" {
+		if slices.Contains(e.aliases, from) {
+			r.logf("env: %s is deprecated, use %s instead", r.prefix+from, r.envName(e))
+		}
+		r.warnIgnoredFile(e, from)
+	}
+
+	// Check whether the variable is required, either always or because of another variable's value.
+	required, reason := r.isRequired(e)
+
+	// Resolve into a temporary value, so that the variable keeps its previous value if it fails to convert or validate.
+	tmp := e
+	tmp.value = reflect.New(reflect.TypeOf(e.value).Elem()).Interface()
+
+	switch {
+	// If the variable is set but empty and that is allowed, keep the empty value.
+	case *e.envValue == "" && e.allowEmpty && r.isSet(r.envName(e)):
+		if err := e.setValue(tmp.value, ""); err != nil {
+			return newParseError(e, r.envName(e), ConversionFailed, err)
+		}
+		*e.source = sourceEnv
+
+	// If the variable is empty and it's not required, set its default value.
+	case *e.envValue == "" && !required:
+		if err := r.applyDefault(tmp); err != nil {
+			return err
+		}
+		*e.source = sourceDefault
+
+	// If the variable is empty but required, return an error.
+	// An indexed variable is named after its first numbered variable, which is the one that is missing.
+	case *e.envValue == "" && required:
+		name := r.envName(e)
+		if e.indexed {
+			name += "_0"
+		}
+		return newParseError(e, name, MissingRequired, fmt.Errorf("%s should be provided%s", name, reason))
+
+	// Otherwise try setting the value using a method that processes it.
+	default:
+		err := e.setValue(tmp.value, *e.envValue)
+		if err != nil {
+			return newParseError(e, r.envName(e), ConversionFailed, err)
+		}
+		*e.source = source
+	}
+
+	// Run any validators against the resolved value.
+	if err := tmp.validate(ctx); err != nil {
+		*e.source = ""
+		return newParseError(e, r.envName(e), ValidationFailed, err)
+	}
+
+	// Assign the resolved value now that it is known to be valid.
+	reflect.ValueOf(e.value).Elem().Set(reflect.ValueOf(tmp.value).Elem())
+	if e.onSet != nil {
+		e.onSet(e.value)
+	}
+
+	// Report the resolution to the hook, never passing a secret's raw value.
+	if r.hook != nil {
+		raw := *e.envValue
+		if e.secret && raw != "" {
+			raw = redacted
+		}
+		r.hook(r.envName(e), e.varType, *e.source != sourceDefault, raw)
+	}
+
+	// Return nil if everything is successful.
+	return nil
+}
+
+// applyDefault sets e to its default, computing it with the DefaultFunc option if one was given.
+// Any error returned is a *ParseError.
+func (r *Registry) applyDefault(e envVar) error {
+	if e.defaultFunc == nil {
+		e.setDefault(e.value, e.defaultValue)
+		return nil
+	}
+
+	s, err := e.defaultFunc()
+	if err != nil {
+		return newParseError(e, r.envName(e), DefaultFailed, err)
+	}
+
+	// Fall back to the static default if the function computed nothing.
+	if s == "" {
+		e.setDefault(e.value, e.defaultValue)
+		return nil
+	}
+
+	if err := e.setValue(e.value, s); err != nil {
+		return newParseError(e, r.envName(e), ConversionFailed, err)
+	}
+
+	return nil
+}
+
+// Help generates and returns a help message listing all environment variables.
+func Help() string {
+	return defaultRegistry.Help()
+}
+
+// Help generates and returns a help message listing the environment variables registered with r.
+func (r *Registry) Help() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Initialize the help message with a title.
+	h := make([]string, 1)
+	h[0] = r.helpTitle()
+
+	// Iterate through all environment variables to generate their descriptions.
+	for _, e := range r.envs {
+		def := quoteDefault(e)
 
-		// Append the variable name and default value to the help message.
-		h = append(h, "  "+e.name+" default: "+def)
+		// Append the variable name, type and default value to the help message.
+		h = append(h, "  "+r.helpName(e)+" type: "+e.varType+" default: "+def)
 		h = append(h, "       ") // Add a blank line for better readability.
 	}
 
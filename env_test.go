@@ -1,12 +1,21 @@
 package env
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/stretchr/testify/assert/"
+	"github.com/stretchr/testify/assert"
 )
 
 func setEnv(name, value string) func() {
@@ -18,7 +27,7 @@ func setEnv(name, value string) func() {
 }
 
 func TestStringSetEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "is awesome")
 	defer cleanup()
 
@@ -29,7 +38,7 @@ func TestStringSetEnv(t *testing.T) {
 }
 
 func TestEmptyStringRequiredTrueEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	testEnv := "nic"
 	os.Unsetenv(testEnv)
 	required := true
@@ -40,7 +49,7 @@ func TestEmptyStringRequiredTrueEnv(t *testing.T) {
 }
 
 func TestEmptyStringRequiredFalseEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	testEnv := "nic"
 	os.Unsetenv(testEnv)
 	required := false
@@ -53,7 +62,7 @@ func TestEmptyStringRequiredFalseEnv(t *testing.T) {
 }
 
 func TestIntSetEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "1")
 	defer cleanup()
 
@@ -64,18 +73,148 @@ func TestIntSetEnv(t *testing.T) {
 }
 
 func TestIntError(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "a")
 	defer cleanup()
 
 	Int("nic", false, 0, "something")
 	err := Parse()
 
-	assert.Contains(t, "expected: nic type: integer got: a", err.Error())
+	assert.Contains(t, err.Error(), "expected: nic type: integer got: a")
+}
+
+func TestIntBoundaries(t *testing.T) {
+	Reset()
+	maxInt := strconv.Itoa(math.MaxInt)
+	minInt := strconv.Itoa(math.MinInt)
+	cleanupMax := setEnv("max", maxInt)
+	defer cleanupMax()
+	cleanupMin := setEnv("min", minInt)
+	defer cleanupMin()
+
+	max := Int("max", true, 0, "something")
+	min := Int("min", true, 0, "something")
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, math.MaxInt, *max)
+	assert.Equal(t, math.MinInt, *min)
+}
+
+func TestIntOverflow(t *testing.T) {
+	Reset()
+	overflow := strconv.FormatUint(uint64(math.MaxInt)+1, 10)
+	cleanup := setEnv("nic", overflow)
+	defer cleanup()
+
+	Int("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: integer got: "+overflow)
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
+
+func TestInt64SetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "9223372036854775807")
+	defer cleanup()
+
+	n := Int64("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, int64(9223372036854775807), *n)
+}
+
+func TestInt64Error(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a")
+	defer cleanup()
+
+	Int64("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: int64 got: a")
+}
+
+func TestInt32SetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "-2147483648")
+	defer cleanup()
+
+	n := Int32("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, int32(-2147483648), *n)
+}
+
+func TestInt32Overflow(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "2147483648")
+	defer cleanup()
+
+	Int32("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: int32 got: 2147483648")
+}
+
+func TestUintSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "42")
+	defer cleanup()
+
+	n := Uint("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, uint(42), *n)
+}
+
+func TestUintNegative(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "-1")
+	defer cleanup()
+
+	Uint("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: uint got: -1")
+}
+
+func TestUint64SetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "18446744073709551615")
+	defer cleanup()
+
+	n := Uint64("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, uint64(18446744073709551615), *n)
+}
+
+func TestUint64Overflow(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "18446744073709551616")
+	defer cleanup()
+
+	Uint64("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: uint64 got: 18446744073709551616")
+}
+
+func TestUint64Negative(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "-1")
+	defer cleanup()
+
+	Uint64("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: uint64 got: -1")
 }
 
 func TestFloat64SetEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "1.1")
 	defer cleanup()
 
@@ -86,18 +225,40 @@ func TestFloat64SetEnv(t *testing.T) {
 }
 
 func TestFloat64Error(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "a")
 	defer cleanup()
 
 	Float64("nic", false, 0, "something")
 	err := Parse()
 
-	assert.Contains(t, "expected: nic type: float got: a", err.Error())
+	assert.Contains(t, err.Error(), "expected: nic type: float got: a")
+}
+
+func TestFloat32SetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1.5")
+	defer cleanup()
+
+	n := Float32("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, float32(1.5), *n)
+}
+
+func TestFloat32Overflow(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1e39")
+	defer cleanup()
+
+	Float32("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: float32 got: 1e39")
 }
 
 func TestBoolSetEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "true")
 	defer cleanup()
 
@@ -108,18 +269,18 @@ func TestBoolSetEnv(t *testing.T) {
 }
 
 func TestBoolError(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "a")
 	defer cleanup()
 
 	Bool("nic", false, false, "something")
 	err := Parse()
 
-	assert.Contains(t, "expected: nic type: boolean got: a", err.Error())
+	assert.Contains(t, err.Error(), "expected: nic type: boolean got: a")
 }
 
 func TestDurationSetEnv(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "10s")
 	defer cleanup()
 
@@ -129,32 +290,1299 @@ func TestDurationSetEnv(t *testing.T) {
 	assert.Equal(t, 10*time.Second, *n)
 }
 
+func TestDurationMilliseconds(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1500ms")
+	defer cleanup()
+
+	n := Duration("nic", false, 1*time.Second, "something")
+	Parse()
+
+	assert.Equal(t, 1500*time.Millisecond, *n)
+}
+
+func TestDurationCompound(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "2h30m")
+	defer cleanup()
+
+	n := Duration("nic", false, 1*time.Second, "something")
+	Parse()
+
+	assert.Equal(t, 2*time.Hour+30*time.Minute, *n)
+}
+
 func TestDurationError(t *testing.T) {
-	envs = make([]envVar, 0)
+	Reset()
 	cleanup := setEnv("nic", "test")
 	defer cleanup()
 
 	Duration("nic", false, 1*time.Second, "something")
 	err := Parse()
 
-	assert.Contains(t, "expected: nic type: duration got: test", err.Error())
+	assert.Contains(t, err.Error(), "expected: nic type: duration got: test")
 }
 
-func TestSetsDefault(t *testing.T) {
-	envs = make([]envVar, 0)
+func TestTimeSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "2024-01-02T15:04:05Z")
+	defer cleanup()
 
-	n := String("nic", false, "is unset", "something")
+	n := Time("nic", false, time.Time{}, "", "something")
 	Parse()
 
-	assert.Equal(t, "is unset", *n)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), *n)
 }
 
-func TestHelp(t *testing.T) {
-	envs = make([]envVar, 0)
-	String("SERVER_URI", true, "localhost:8181", "URI for upstream server, i.e. localhost:8181")
-	String("API_KEY", true, "", "API key for upstream server")
-	Int("TIMEOUT", true, 12, "Timeout duration in seconds")
-	h := Help()
+func TestTimeLayout(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "2024-01-02")
+	defer cleanup()
 
-	fmt.Println(h)
+	n := Time("nic", false, time.Time{}, time.DateOnly, "something")
+	Parse()
+
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), *n)
+}
+
+func TestTimeError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "yesterday")
+	defer cleanup()
+
+	Time("nic", false, time.Time{}, time.DateOnly, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: time got: yesterday")
+	assert.Contains(t, err.Error(), `expected layout "2006-01-02"`)
+}
+
+func TestURLSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "https://api.example.com/v1?x=1")
+	defer cleanup()
+
+	n := URL("nic", true, "", "something")
+	Parse()
+
+	assert.Equal(t, "https", n.Scheme)
+	assert.Equal(t, "api.example.com", n.Host)
+	assert.Equal(t, "/v1", n.Path)
+	assert.Equal(t, "x=1", n.RawQuery)
+}
+
+func TestURLDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	n := URL("nic", false, "http://localhost:8080", "something")
+	Parse()
+
+	assert.Equal(t, "http://localhost:8080", n.String())
+}
+
+func TestURLError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "/relative/path")
+	defer cleanup()
+
+	URL("nic", false, "", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: url got: /relative/path")
+}
+
+func TestURLInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() {
+		URL("nic", false, "not a url", "something")
+	})
+}
+
+func TestIPSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "10.0.0.1")
+	defer cleanup()
+
+	n := IP("nic", true, "", "something")
+	Parse()
+
+	assert.Equal(t, "10.0.0.1", n.String())
+}
+
+func TestIPv6SetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "::1")
+	defer cleanup()
+
+	n := IP("nic", true, "", "something")
+	Parse()
+
+	assert.True(t, n.IsLoopback())
+	assert.Nil(t, n.To4())
+}
+
+func TestIPError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "not.an.ip")
+	defer cleanup()
+
+	IP("nic", false, "", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: ip got: not.an.ip")
+}
+
+func TestIPInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() {
+		IP("nic", false, "localhost", "something")
+	})
+}
+
+func TestCIDRSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "10.1.2.3/8")
+	defer cleanup()
+
+	n := CIDR("nic", true, "", "something")
+	Parse()
+
+	assert.Equal(t, "10.0.0.0/8", n.String())
+	assert.True(t, n.Contains(net.ParseIP("10.200.0.1")))
+}
+
+func TestCIDRError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "10.0.0.0/33")
+	defer cleanup()
+
+	CIDR("nic", false, "", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: cidr got: 10.0.0.0/33")
+}
+
+func TestCIDRInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() {
+		CIDR("nic", false, "10.0.0.0", "something")
+	})
+}
+
+func TestStringSliceSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", " a.com, b.com,,c.com ")
+	defer cleanup()
+
+	n := StringSlice("nic", true, nil, "", "something")
+	Parse()
+
+	assert.Equal(t, []string{"a.com", "b.com", "c.com"}, *n)
+}
+
+func TestStringSliceDelimiter(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a;b;c")
+	defer cleanup()
+
+	n := StringSlice("nic", true, nil, ";", "something")
+	Parse()
+
+	assert.Equal(t, []string{"a", "b", "c"}, *n)
+}
+
+func TestStringSliceDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	n := StringSlice("nic", false, []string{"x", "y"}, ",", "something")
+	Parse()
+
+	assert.Equal(t, []string{"x", "y"}, *n)
+}
+
+func TestIntSliceSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "100, 200 ,400")
+	defer cleanup()
+
+	n := IntSlice("nic", true, nil, "", "something")
+	Parse()
+
+	assert.Equal(t, []int{100, 200, 400}, *n)
+}
+
+func TestIntSliceError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1,two,3")
+	defer cleanup()
+
+	IntSlice("nic", false, nil, ",", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: []integer got: 1,two,3")
+	assert.Contains(t, err.Error(), `invalid element "two"`)
+}
+
+func TestMapSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "team=core, env=prod,team=edge")
+	defer cleanup()
+
+	n := Map("nic", true, nil, "", "", "something")
+	Parse()
+
+	assert.Equal(t, map[string]string{"team": "edge", "env": "prod"}, *n)
+}
+
+func TestMapSeparators(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a:1;b:2")
+	defer cleanup()
+
+	n := Map("nic", true, nil, ";", ":", "something")
+	Parse()
+
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, *n)
+}
+
+func TestMapError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a=1,b")
+	defer cleanup()
+
+	Map("nic", false, nil, ",", "=", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: map[string]string got: a=1,b")
+	assert.Contains(t, err.Error(), `invalid entry "b"`)
+}
+
+func TestBytesSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "c2VjcmV0")
+	defer cleanup()
+
+	n := Bytes("nic", true, nil, "something")
+	Parse()
+
+	assert.Equal(t, []byte("secret"), *n)
+}
+
+func TestBytesError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "not*base64")
+	defer cleanup()
+
+	Bytes("nic", false, nil, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: base64 got: ****")
+	assert.NotContains(t, err.Error(), "not*base64")
+}
+
+func TestByteSizeSetEnv(t *testing.T) {
+	Reset()
+
+	tests := map[string]int64{
+		"512":    512,
+		"10MB":   10 * 1000 * 1000,
+		"2 kb":   2000,
+		"1KiB":   1024,
+		"3GiB":   3 << 30,
+		"1TB":    1000 * 1000 * 1000 * 1000,
+		"100B":   100,
+		"4 MiB ": 4 << 20,
+	}
+	for in, want := range tests {
+		n, err := parseByteSize(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, n, in)
+	}
+
+	cleanup := setEnv("nic", "10MB")
+	defer cleanup()
+
+	n := ByteSize("nic", true, 0, "something")
+	Parse()
+
+	assert.Equal(t, int64(10*1000*1000), *n)
+}
+
+func TestByteSizeError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "10XB")
+	defer cleanup()
+
+	ByteSize("nic", false, 0, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: bytesize got: 10XB")
+	assert.Contains(t, err.Error(), `unknown size unit "XB"`)
+}
+
+func TestEnumSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "warn")
+	defer cleanup()
+
+	n := Enum("nic", true, "info", []string{"debug", "info", "warn"}, "something")
+	Parse()
+
+	assert.Equal(t, "warn", *n)
+}
+
+func TestEnumError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "trace")
+	defer cleanup()
+
+	Enum("nic", false, "info", []string{"debug", "info", "warn"}, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: enum got: trace")
+	assert.Contains(t, err.Error(), "must be one of [debug, info, warn]")
+}
+
+func TestEnumInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() {
+		Enum("nic", false, "trace", []string{"debug", "info"}, "something")
+	})
+}
+
+func TestRegexpSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "^/api/v[0-9]+/")
+	defer cleanup()
+
+	n := Regexp("nic", true, "", "something")
+	Parse()
+
+	assert.True(t, n.MatchString("/api/v2/users"))
+	assert.False(t, n.MatchString("/static/app.js"))
+}
+
+func TestRegexpDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	n := Regexp("nic", false, "^a+$", "something")
+	Parse()
+
+	assert.True(t, n.MatchString("aaa"))
+}
+
+func TestRegexpError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a(b")
+	defer cleanup()
+
+	Regexp("nic", false, "", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: regexp got: a(b")
+	assert.Contains(t, err.Error(), "missing closing )")
+}
+
+func TestRegexpInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() {
+		Regexp("nic", false, "[", "something")
+	})
+}
+
+func TestJSONSetEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", `{"name":"nic","tags":["a","b"]}`)
+	defer cleanup()
+
+	type config struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	n := JSON("nic", true, config{}, "something")
+	Parse()
+
+	assert.Equal(t, config{Name: "nic", Tags: []string{"a", "b"}}, *n)
+}
+
+func TestJSONDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	n := JSON("nic", false, map[string]bool{"a": true}, "something")
+	Parse()
+
+	assert.Equal(t, map[string]bool{"a": true}, *n)
+}
+
+func TestJSONNilInterfaceDefault(t *testing.T) {
+	r := NewRegistry()
+	os.Unsetenv("nic")
+
+	n := JSONIn[any](r, "nic", false, nil, "something")
+
+	assert.NoError(t, r.Parse())
+	assert.Nil(t, *n)
+}
+
+func TestJSONDefaultIsCopied(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	def := map[string][]string{"a": {"x"}}
+	n := JSON("nic", false, def, "something")
+	assert.NoError(t, Parse())
+
+	(*n)["b"] = []string{"y"}
+	(*n)["a"][0] = "changed"
+	assert.Equal(t, map[string][]string{"a": {"x"}}, def)
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, map[string][]string{"a": {"x"}}, *n)
+}
+
+func TestJSONError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", `{"a":`)
+	defer cleanup()
+
+	JSON("nic", false, map[string]bool{}, "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), `expected: nic type: json got: {"a":`)
+}
+
+func TestParseReportsAllErrors(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a")
+	defer cleanup()
+	os.Unsetenv("missing")
+
+	Int("nic", false, 0, "something")
+	String("missing", true, "", "something")
+	Bool("nic", false, false, "something")
+	err := Parse()
+
+	assert.Error(t, err)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	assert.Contains(t, err.Error(), "expected: nic type: integer got: a")
+	assert.Contains(t, err.Error(), "expected: missing type: string got: ")
+	assert.Contains(t, err.Error(), "expected: nic type: boolean got: a")
+}
+
+func TestParseWrapsCause(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "99999999999999999999")
+	defer cleanup()
+
+	Int("nic", false, 0, "something")
+	err := Parse()
+
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
+
+func TestSetPrefix(t *testing.T) {
+	Reset()
+	SetPrefix("SVC_A_")
+	defer SetPrefix("")
+	cleanup := setEnv("SVC_A_nic", "prefixed")
+	defer cleanup()
+	cleanupUnprefixed := setEnv("nic", "unprefixed")
+	defer cleanupUnprefixed()
+
+	n := String("nic", false, "", "something")
+	Parse()
+
+	assert.Equal(t, "prefixed", *n)
+	assert.Contains(t, Help(), "  SVC_A_nic type: string")
+}
+
+func TestSetPrefixError(t *testing.T) {
+	Reset()
+	SetPrefix("SVC_A_")
+	defer SetPrefix("")
+	os.Unsetenv("SVC_A_nic")
+
+	String("nic", true, "", "something")
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: SVC_A_nic type: string")
+}
+
+func TestSetPrefixFromEnv(t *testing.T) {
+	Reset()
+	cleanup := setEnv("CONFIG_PREFIX", "TENANT_A_")
+	defer cleanup()
+	cleanup2 := setEnv("TENANT_A_nic", "tenant")
+	defer cleanup2()
+	cleanup3 := setEnv("nic", "unprefixed")
+	defer cleanup3()
+
+	SetPrefixFromEnv("CONFIG_PREFIX")
+	n := String("nic", false, "", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "tenant", *n)
+	assert.Contains(t, Help(), "  TENANT_A_nic type: string")
+
+	// Without the prefix variable, no prefix is used.
+	os.Unsetenv("CONFIG_PREFIX")
+	assert.NoError(t, Parse())
+	assert.Equal(t, "unprefixed", *n)
+
+	// A later SetPrefix replaces it.
+	cleanup4 := setEnv("CONFIG_PREFIX", "TENANT_A_")
+	defer cleanup4()
+	SetPrefix("")
+	assert.NoError(t, Parse())
+	assert.Equal(t, "unprefixed", *n)
+}
+
+func TestMustParse(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+	os.Unsetenv("other")
+
+	String("nic", true, "", "something")
+	String("other", true, "", "something")
+
+	assert.PanicsWithError(t, "env: expected: nic type: string got: : nic should be provided\n"+
+		"expected: other type: string got: : other should be provided", MustParse)
+}
+
+func TestMustParseSuccess(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "is awesome")
+	defer cleanup()
+
+	n := String("nic", true, "", "something")
+
+	assert.NotPanics(t, MustParse)
+	assert.Equal(t, "is awesome", *n)
+}
+
+func TestReset(t *testing.T) {
+	Reset()
+	SetPrefix("SVC_")
+	*help = true
+	String("nic", false, "", "something")
+
+	Reset()
+
+	assert.Empty(t, defaultRegistry.envs)
+	assert.Empty(t, defaultRegistry.prefix)
+	assert.False(t, *help)
+}
+
+func TestIndexedStringSlice(t *testing.T) {
+	Reset()
+	os.Unsetenv("ARG_2")
+	os.Unsetenv("EMPTY_0")
+	cleanup := setEnv("ARG_0", "--name")
+	defer cleanup()
+	cleanup2 := setEnv("ARG_1", " hello, world ")
+	defer cleanup2()
+	cleanup3 := setEnv("ARG_3", "ignored")
+	defer cleanup3()
+
+	args := IndexedStringSlice("ARG", true, "something")
+	empty := IndexedStringSlice("EMPTY", false, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"--name", " hello, world "}, *args)
+	assert.Empty(t, *empty)
+	assert.Equal(t, "env", Source("ARG"))
+
+	os.Unsetenv("ARG_0")
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ARG_0 should be provided")
+
+	SetPrefix("APP_")
+	defer SetPrefix("")
+	err = Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "APP_ARG_0 should be provided")
+}
+
+func TestCheck(t *testing.T) {
+	Reset()
+	os.Unsetenv("API_KEY")
+	cleanup := setEnv("PORT", "9090")
+	defer cleanup()
+	cleanup2 := setEnv("LOG_LEVEL", "warn")
+	defer cleanup2()
+
+	port := Int("PORT", false, 8080, "something")
+	level, idx := Choices("LOG_LEVEL", false, "info", []string{"debug", "info", "warn"}, "something")
+	String("API_KEY", true, "", "something")
+
+	err := Check()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "API_KEY should be provided")
+
+	// The pointers are left untouched, and Source reports nothing was resolved.
+	assert.Equal(t, 0, *port)
+	assert.Equal(t, "", *level)
+	assert.Equal(t, -1, *idx)
+	assert.Equal(t, "", Source("PORT"))
+
+	// Parse returns the same error.
+	assert.Equal(t, err.Error(), Parse().Error())
+	assert.Equal(t, 9090, *port)
+	assert.Equal(t, 2, *idx)
+}
+
+func TestParseKeepsValueOnValidationFailure(t *testing.T) {
+	Reset()
+	cleanup := setEnv("API_TOKEN", "long enough")
+	defer cleanup()
+
+	tok := String("API_TOKEN", true, "", "something", Length(8, 0))
+	level, idx := Choices("LOG_LEVEL", false, "info", []string{"debug", "info"}, "something", Validate(func(v interface{}) error {
+		if v == "debug" {
+			return errors.New("debug is not allowed")
+		}
+		return nil
+	}))
+	assert.NoError(t, Parse())
+
+	cleanup2 := setEnv("API_TOKEN", "abc")
+	defer cleanup2()
+	cleanup3 := setEnv("LOG_LEVEL", "debug")
+	defer cleanup3()
+
+	assert.Error(t, Parse())
+	assert.Equal(t, "long enough", *tok)
+	assert.Equal(t, "info", *level)
+	assert.Equal(t, 1, *idx)
+}
+
+func TestParseCopiesDefaults(t *testing.T) {
+	Reset()
+	os.Unsetenv("BIND_IP")
+	os.Unsetenv("ALLOWED_NET")
+	os.Unsetenv("LIMITS")
+
+	ip := IP("BIND_IP", false, "10.0.0.1", "something")
+	network := CIDR("ALLOWED_NET", false, "10.0.0.0/8", "something")
+	limits := JSON("LIMITS", false, map[string]int{"rps": 10}, "something")
+	assert.NoError(t, Parse())
+
+	(*ip)[len(*ip)-1] = 99
+	network.IP[0] = 192
+	(*limits)["rps"] = 1000
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "10.0.0.1", ip.String())
+	assert.Equal(t, "10.0.0.0/8", network.String())
+	assert.Equal(t, map[string]int{"rps": 10}, *limits)
+}
+
+func TestParseFrom(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "from process")
+	defer cleanup()
+
+	vars := map[string]string{"nic": "from map", "port": "a"}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	n := String("nic", true, "", "something")
+	m := String("missing", false, "default", "something")
+	Int("port", false, 0, "something")
+	err := ParseFrom(lookup)
+
+	assert.Equal(t, "from map", *n)
+	assert.Equal(t, "default", *m)
+	assert.ErrorContains(t, err, "expected: port type: integer got: a")
+
+	delete(vars, "port")
+	assert.NoError(t, Parse())
+	assert.Equal(t, "from process", *n)
+}
+
+func TestParseIsIdempotent(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a,b")
+	defer cleanup()
+	os.Unsetenv("other")
+
+	n := StringSlice("nic", false, nil, ",", "something")
+	m := Map("other", false, map[string]string{"k": "v"}, ",", "=", "something")
+	assert.NoError(t, Parse())
+
+	(*n)[0] = "changed"
+	(*m)["k"] = "changed"
+	assert.NoError(t, Parse())
+
+	assert.Equal(t, []string{"a", "b"}, *n)
+	assert.Equal(t, map[string]string{"k": "v"}, *m)
+	assert.Equal(t, []string{"other"}, Defaulted())
+}
+
+func TestSetHook(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1")
+	defer cleanup()
+	cleanupSecret := setEnv("secret", "hunter2")
+	defer cleanupSecret()
+	os.Unsetenv("other")
+
+	calls := make([]string, 0)
+	SetHook(func(name, varType string, fromEnv bool, rawValue string) {
+		calls = append(calls, fmt.Sprintf("%s %s %t %q", name, varType, fromEnv, rawValue))
+	})
+
+	Int("nic", false, 0, "something")
+	String("other", false, "x", "something")
+	String("secret", false, "", "something", Secret())
+	Int("bad", false, 0, "something", Validate(func(interface{}) error { return fmt.Errorf("nope") }))
+	Parse()
+
+	assert.Equal(t, []string{
+		`nic integer true "1"`,
+		`other string false ""`,
+		`secret string true "****"`,
+	}, calls)
+}
+
+func TestSetsDefault(t *testing.T) {
+	Reset()
+
+	n := String("nic", false, "is unset", "something")
+	Parse()
+
+	assert.Equal(t, "is unset", *n)
+}
+
+func TestHelp(t *testing.T) {
+	Reset()
+	String("SERVER_URI", true, "localhost:8181", "URI for upstream server, i.e. localhost:8181")
+	String("API_KEY", true, "", "API key for upstream server")
+	Int("TIMEOUT", true, 12, "Timeout duration in seconds")
+	Duration("INTERVAL", false, 5*time.Second, "Polling interval")
+	h := Help()
+
+	fmt.Println(h)
+
+	assert.Contains(t, h, "  SERVER_URI type: string default: 'localhost:8181'")
+	assert.Contains(t, h, "  INTERVAL type: duration default: '5s'")
+}
+
+func TestSetDefaultDelimiter(t *testing.T) {
+	Reset()
+	cleanup := setEnv("HOSTS", "a;b")
+	defer cleanup()
+	cleanup2 := setEnv("PORTS", "1,2")
+	defer cleanup2()
+	cleanup3 := setEnv("LABELS", "a=1;b=2")
+	defer cleanup3()
+
+	SetDefaultDelimiter(";")
+	hosts := StringSlice("HOSTS", false, nil, "", "something")
+	ports := IntSlice("PORTS", false, nil, ",", "something")
+	labels := Map("LABELS", false, nil, "", "", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"a", "b"}, *hosts)
+	assert.Equal(t, []int{1, 2}, *ports)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, *labels)
+}
+
+func TestPort(t *testing.T) {
+	Reset()
+	cleanup := setEnv("PORT", "9090")
+	defer cleanup()
+
+	port := Port("PORT", true, 0, "something")
+	admin := Port("ADMIN_PORT", false, 8081, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, 9090, *port)
+	assert.Equal(t, 8081, *admin)
+}
+
+func TestPortOutOfRange(t *testing.T) {
+	for _, value := range []string{"0", "70000", "-1"} {
+		Reset()
+		cleanup := setEnv("PORT", value)
+
+		Port("PORT", true, 0, "something")
+
+		err := Parse()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected: PORT type: port got: "+value+": port "+value+" out of range 1-65535")
+		cleanup()
+	}
+}
+
+func TestPortInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { Port("PORT", false, 70000, "something") })
+}
+
+func TestTLSVersion(t *testing.T) {
+	Reset()
+	os.Unsetenv("TLS_MAX_VERSION")
+	cleanup := setEnv("TLS_MIN_VERSION", "1.2")
+	defer cleanup()
+	cleanup2 := setEnv("TLS_LEGACY_VERSION", "TLS1.2")
+	defer cleanup2()
+
+	minVersion := TLSVersion("TLS_MIN_VERSION", true, 0, "something")
+	maxVersion := TLSVersion("TLS_MAX_VERSION", false, tls.VersionTLS13, "something")
+	TLSVersion("TLS_LEGACY_VERSION", true, 0, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `expected: TLS_LEGACY_VERSION type: tls version got: TLS1.2: unknown TLS version "TLS1.2", must be one of [1.0, 1.1, 1.2, 1.3]`)
+	assert.Equal(t, uint16(tls.VersionTLS12), *minVersion)
+	assert.Equal(t, uint16(tls.VersionTLS13), *maxVersion)
+	assert.Contains(t, Help(), "TLS_MAX_VERSION type: tls version default: '1.3'")
+	assert.Panics(t, func() { TLSVersion("TLS_MIN_VERSION", false, 42, "something") })
+}
+
+func TestLogLevel(t *testing.T) {
+	Reset()
+	os.Unsetenv("AUDIT_LOG_LEVEL")
+	os.Unsetenv("ACCESS_LOG_LEVEL")
+	cleanup := setEnv("LOG_LEVEL", "WARN")
+	defer cleanup()
+	cleanup2 := setEnv("DB_LOG_LEVEL", "verbose")
+	defer cleanup2()
+
+	level := LogLevel("LOG_LEVEL", true, "", "something")
+	audit := LogLevel("AUDIT_LOG_LEVEL", false, "debug", "something")
+	access := LogLevel("ACCESS_LOG_LEVEL", false, "", "something")
+	LogLevel("DB_LOG_LEVEL", false, "info", "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `expected: DB_LOG_LEVEL type: log level got: verbose: unknown log level "verbose", must be one of [debug, info, warn, error]`)
+	assert.Equal(t, slog.LevelWarn, *level)
+	assert.Equal(t, slog.LevelDebug, *audit)
+	assert.Equal(t, slog.LevelInfo, *access)
+	assert.Contains(t, Help(), "AUDIT_LOG_LEVEL type: log level default: 'debug'")
+	assert.Panics(t, func() { LogLevel("LOG_LEVEL", false, "verbose", "something") })
+}
+
+func TestFilePath(t *testing.T) {
+	Reset()
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	assert.NoError(t, os.WriteFile(path, []byte("cert"), 0o600))
+	cleanup := setEnv("CERT_FILE", path)
+	defer cleanup()
+
+	cert := FilePath("CERT_FILE", true, "", true, "something")
+	key := FilePath("KEY_FILE", false, "missing.pem", false, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, path, *cert)
+	assert.Equal(t, "missing.pem", *key)
+}
+
+func TestFilePathMustExist(t *testing.T) {
+	Reset()
+	dir := t.TempDir()
+	cleanup := setEnv("CERT_DIR", dir)
+	defer cleanup()
+
+	FilePath("CERT_FILE", false, filepath.Join(dir, "missing.pem"), true, "something")
+	FilePath("CERT_DIR", false, "", true, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: CERT_FILE type: path got: : open "+filepath.Join(dir, "missing.pem"))
+	assert.Contains(t, err.Error(), dir+" is a directory")
+}
+
+func TestStringSliceQuoted(t *testing.T) {
+	Reset()
+	cleanup := setEnv("AGENTS", `"Mozilla/5.0 (X11, Linux)", curl , "say \"hi\"",""`)
+	defer cleanup()
+
+	agents := StringSlice("AGENTS", true, nil, ",", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"Mozilla/5.0 (X11, Linux)", "curl", `say "hi"`, ""}, *agents)
+}
+
+func TestStringSliceQuotedInvalid(t *testing.T) {
+	for _, value := range []string{`"a,b`, `"a"b,c`} {
+		Reset()
+		cleanup := setEnv("AGENTS", value)
+
+		StringSlice("AGENTS", true, nil, ",", "something")
+
+		assert.Error(t, Parse())
+		cleanup()
+	}
+}
+
+func TestQuoteElement(t *testing.T) {
+	for _, p := range []string{"plain", "a,b", ` padded `, `say "hi"`, `back\slash"`, ""} {
+		parts, err := splitQuoted(quoteElement(p, ",")+",x", ",")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{p, "x"}, parts)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	Reset()
+	cleanup := setEnv("ALERT_EMAIL", "Alerts <alerts@example.com>")
+	defer cleanup()
+
+	alert := Email("ALERT_EMAIL", true, "", "something")
+	from := Email("FROM_EMAIL", false, "noreply@example.com", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "alerts@example.com", *alert)
+	assert.Equal(t, "noreply@example.com", *from)
+}
+
+func TestEmailInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("ALERT_EMAIL", "alerts.example.com")
+	defer cleanup()
+
+	Email("ALERT_EMAIL", true, "", "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: ALERT_EMAIL type: email got: alerts.example.com: mail: missing '@' or angle-addr")
+	assert.Panics(t, func() { Email("FROM_EMAIL", false, "nope", "something") })
+}
+
+func TestHostname(t *testing.T) {
+	Reset()
+	cleanup := setEnv("DB_HOST", "localhost")
+	defer cleanup()
+
+	db := Hostname("DB_HOST", true, "", true, "something")
+	cache := Hostname("CACHE_HOST", false, "cache.invalid", false, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "localhost", *db)
+	assert.Equal(t, "cache.invalid", *cache)
+}
+
+func TestHostnameUnresolved(t *testing.T) {
+	Reset()
+	cleanup := setEnv("DB_HOST", "db.invalid")
+	defer cleanup()
+
+	Hostname("DB_HOST", true, "", true, "something")
+
+	err := Parse()
+	var pe *ParseError
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, ValidationFailed, pe.Kind)
+	assert.Contains(t, err.Error(), "expected: DB_HOST type: hostname got: db.invalid: ")
+}
+
+func TestOneOfInt(t *testing.T) {
+	Reset()
+	cleanup := setEnv("VERBOSITY", "2")
+	defer cleanup()
+
+	verbosity := OneOfInt("VERBOSITY", true, 0, []int{0, 1, 2, 3}, "something")
+	workers := OneOfInt("WORKERS", false, 4, []int{1, 2, 4, 8}, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, 2, *verbosity)
+	assert.Equal(t, 4, *workers)
+}
+
+func TestOneOfIntInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("VERBOSITY", "5")
+	defer cleanup()
+
+	OneOfInt("VERBOSITY", true, 0, []int{0, 1, 2, 3}, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: VERBOSITY type: integer got: 5: must be one of [0, 1, 2, 3]")
+	assert.Panics(t, func() { OneOfInt("WORKERS", false, 3, []int{1, 2, 4, 8}, "something") })
+}
+
+func TestFlexBool(t *testing.T) {
+	for value, want := range map[string]bool{"YES": true, "no": false, "On": true, "off": false, "enabled": true, "DISABLED": false, "true": true, "0": false} {
+		Reset()
+		cleanup := setEnv("METRICS", value)
+
+		metrics := FlexBool("METRICS", true, false, "something")
+
+		assert.NoError(t, Parse())
+		assert.Equal(t, want, *metrics, value)
+		cleanup()
+	}
+}
+
+func TestFlexBoolInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("METRICS", "maybe")
+	defer cleanup()
+
+	FlexBool("METRICS", true, false, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: METRICS type: boolean got: maybe: must be one of [true, false, 1, 0, t, f, yes, no, on, off, enabled, disabled]")
+}
+
+func TestStringMatch(t *testing.T) {
+	Reset()
+	cleanup := setEnv("TENANT_SLUG", "acme-1")
+	defer cleanup()
+
+	slug := StringMatch("TENANT_SLUG", true, "", `[a-z0-9-]+`, "something")
+	region := StringMatch("REGION", false, "eu", `eu|us`, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "acme-1", *slug)
+	assert.Equal(t, "eu", *region)
+}
+
+func TestStringMatchInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("TENANT_SLUG", "Acme 1")
+	defer cleanup()
+	cleanup2 := setEnv("REGION", "europe")
+	defer cleanup2()
+
+	StringMatch("TENANT_SLUG", true, "", `[a-z0-9-]+`, "something")
+	StringMatch("REGION", false, "", `eu|us`, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: TENANT_SLUG type: string got: Acme 1: must match [a-z0-9-]+")
+	assert.Contains(t, err.Error(), "expected: REGION type: string got: europe: must match eu|us")
+	assert.Panics(t, func() { StringMatch("SLUG", false, "Not A Slug", `[a-z]+`, "something") })
+	assert.Panics(t, func() { StringMatch("SLUG", false, "", `[a-z`, "something") })
+}
+
+func TestChoices(t *testing.T) {
+	Reset()
+	os.Unsetenv("LOG_FORMAT")
+	cleanup := setEnv("SYNC_MODE", "safe")
+	defer cleanup()
+
+	mode, modeIndex := Choices("SYNC_MODE", false, "fast", []string{"fast", "safe", "paranoid"}, "something")
+	format, formatIndex := Choices("LOG_FORMAT", false, "", []string{"text", "json"}, "something")
+	assert.Equal(t, -1, *modeIndex)
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "safe", *mode)
+	assert.Equal(t, 1, *modeIndex)
+	assert.Equal(t, "", *format)
+	assert.Equal(t, -1, *formatIndex)
+
+	os.Setenv("SYNC_MODE", "turbo")
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: SYNC_MODE type: enum got: turbo: must be one of [fast, safe, paranoid]")
+	assert.Equal(t, 1, *modeIndex)
+}
+
+func TestIntBase(t *testing.T) {
+	Reset()
+	cleanup := setEnv("PERMISSIONS", "0644")
+	defer cleanup()
+	cleanup2 := setEnv("MASK", "ff")
+	defer cleanup2()
+	cleanup3 := setEnv("FLAGS", "0b101")
+	defer cleanup3()
+
+	perm := IntBase("PERMISSIONS", true, 0, 8, "something")
+	mask := IntBase("MASK", true, 0, 16, "something")
+	flags := IntBase("FLAGS", true, 0, 0, "something")
+	umask := IntBase("UMASK", false, 0o22, 8, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, 0o644, *perm)
+	assert.Equal(t, 0xff, *mask)
+	assert.Equal(t, 5, *flags)
+	assert.Equal(t, 0o22, *umask)
+	assert.Contains(t, Help(), "UMASK type: integer default: '22'")
+}
+
+func TestIntBaseInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("PERMISSIONS", "0689")
+	defer cleanup()
+
+	IntBase("PERMISSIONS", true, 0, 8, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `expected: PERMISSIONS type: integer got: 0689: base 8: strconv.ParseInt: parsing "0689": invalid syntax`)
+	assert.Panics(t, func() { IntBase("MASK", false, 0, 1, "something") })
+}
+
+func TestStringSliceUnique(t *testing.T) {
+	Reset()
+	cleanup := setEnv("USERS", "alice,Bob,alice,bob,carol")
+	defer cleanup()
+
+	users := StringSliceUnique("USERS", true, nil, ",", "something")
+	folded := StringSliceUnique("USERS", true, nil, ",", "something", IgnoreCase())
+	def := StringSliceUnique("ROLES", false, []string{"admin", "admin", "viewer"}, ",", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"alice", "Bob", "bob", "carol"}, *users)
+	assert.Equal(t, []string{"alice", "Bob", "carol"}, *folded)
+	assert.Equal(t, []string{"admin", "viewer"}, *def)
+	assert.Panics(t, func() { StringSlice("USERS", true, nil, ",", "something", IgnoreCase()) })
+}
+
+func TestMapMulti(t *testing.T) {
+	Reset()
+	cleanup := setEnv("LABELS", "team=core; env=prod,region=eu;;tier=1")
+	defer cleanup()
+
+	labels := MapMulti("LABELS", true, nil, []string{",", ";", ";;"}, "=", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, map[string]string{"team": "core", "env": "prod", "region": "eu", "tier": "1"}, *labels)
+	assert.Panics(t, func() { MapMulti("TAGS", false, nil, []string{",", ":"}, ":", "something") })
+}
+
+func TestMapMultiInvalid(t *testing.T) {
+	Reset()
+	cleanup := setEnv("LABELS", "team=core;env")
+	defer cleanup()
+
+	MapMulti("LABELS", true, nil, []string{",", ";"}, "=", "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `expected: LABELS type: map[string]string got: team=core;env: invalid entry "env": missing "="`)
+}
+
+func TestGlob(t *testing.T) {
+	Reset()
+	dir := t.TempDir()
+	for _, name := range []string{"b.yaml", "a.yaml", "c.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	cleanup := setEnv("CONFIG_FILES", filepath.Join(dir, "*.yaml"))
+	defer cleanup()
+	cleanup2 := setEnv("EXTRA_FILES", filepath.Join(dir, "*.json"))
+	defer cleanup2()
+
+	files := Glob("CONFIG_FILES", true, "", "something")
+	extra := Glob("EXTRA_FILES", false, "", "something")
+	text := Glob("TEXT_FILES", false, filepath.Join(dir, "*.txt"), "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")}, *files)
+	assert.Empty(t, *extra)
+	assert.Equal(t, []string{filepath.Join(dir, "c.txt")}, *text)
+}
+
+func TestGlobInvalid(t *testing.T) {
+	Reset()
+	dir := t.TempDir()
+	cleanup := setEnv("CONFIG_FILES", filepath.Join(dir, "*.yaml"))
+	defer cleanup()
+	cleanup2 := setEnv("EXTRA_FILES", "[")
+	defer cleanup2()
+
+	Glob("CONFIG_FILES", true, "", "something")
+	Glob("EXTRA_FILES", false, "", "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "matches no files")
+	assert.Contains(t, err.Error(), "expected: EXTRA_FILES type: glob got: [: syntax error in pattern")
+	assert.Panics(t, func() { Glob("TEXT_FILES", false, "[", "something") })
+}
+
+func TestMultiLine(t *testing.T) {
+	Reset()
+	cleanup := setEnv("TLS_CERT", `-----BEGIN CERTIFICATE-----\nMIIB\r\n-----END CERTIFICATE-----`)
+	defer cleanup()
+
+	cert := MultiLine("TLS_CERT", true, "", "something")
+	raw := MultiLine("TLS_CERT", true, "", "something", KeepEscapes())
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----", *cert)
+	assert.Equal(t, `-----BEGIN CERTIFICATE-----\nMIIB\r\n-----END CERTIFICATE-----`, *raw)
+	assert.Panics(t, func() { String("TLS_CERT", true, "", "something", KeepEscapes()) })
+}
+
+func TestInvalidStringDefaults(t *testing.T) {
+	Reset()
+
+	for name, register := range map[string]func(){
+		"URL":         func() { URL("API_URL", false, "://missing-scheme", "something") },
+		"IP":          func() { IP("BIND_IP", false, "999.0.0.1", "something") },
+		"CIDR":        func() { CIDR("ALLOWED_NET", false, "10.0.0.0/99", "something") },
+		"Enum":        func() { Enum("MODE", false, "fast", []string{"slow"}, "something") },
+		"Choices":     func() { Choices("MODE", false, "fast", []string{"slow"}, "something") },
+		"StringMatch": func() { StringMatch("REGION", false, "EU", "[a-z]+", "something") },
+		"Email":       func() { Email("ADMIN_EMAIL", false, "not an email", "something") },
+		"Glob":        func() { Glob("CONFIG_FILES", false, "[", "something") },
+		"Regexp":      func() { Regexp("PATH_FILTER", false, "(", "something") },
+		"LogLevel":    func() { LogLevel("LOG_LEVEL", false, "verbose", "something") },
+	} {
+		assert.Panics(t, register, name)
+	}
+}
+
+func TestValidateChecksStringDefault(t *testing.T) {
+	Reset()
+	lower := Validate(func(v interface{}) error {
+		if s := v.(string); s != strings.ToLower(s) {
+			return errors.New("must be lower case")
+		}
+		return nil
+	})
+
+	assert.PanicsWithValue(t, "env: invalid default value for MODE: must be lower case", func() {
+		String("MODE", false, "FAST", "something", lower)
+	})
+	assert.PanicsWithValue(t, "env: invalid default value for LEVEL: must be lower case", func() {
+		Enum("LEVEL", false, "INFO", []string{"INFO", "debug"}, "something", lower)
+	})
+	assert.PanicsWithValue(t, "env: invalid default value for BASE_URL: must use https", func() {
+		URL("BASE_URL", false, "http://example.com", "something", Validate(func(v interface{}) error {
+			if u := v.(url.URL); u.Scheme != "https" {
+				return errors.New("must use https")
+			}
+			return nil
+		}))
+	})
+
+	// The default of a required variable is never used, so it is not checked.
+	assert.NotPanics(t, func() { String("MODE", true, "FAST", "something", lower) })
+	assert.NotPanics(t, func() { String("NAME", false, "fast", "something", lower) })
+	assert.NotPanics(t, func() { Enum("LEVEL", true, "verbose", []string{"debug"}, "something") })
+
+	// The default is checked in the form Parse() assigns, which for MultiLine keeps its escapes.
+	singleLine := Validate(func(v interface{}) error {
+		if strings.Contains(v.(string), "\n") {
+			return errors.New("must be a single line")
+		}
+		return nil
+	})
+	assert.NotPanics(t, func() { MultiLine("BANNER", false, `hello\nworld`, "something", singleLine) })
+	assert.PanicsWithValue(t, "env: invalid default value for MOTD: must be a single line", func() {
+		MultiLine("MOTD", false, "hello\nworld", "something", singleLine)
+	})
 }
@@ -0,0 +1,155 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind describes why an environment variable could not be parsed.
+type ErrorKind int
+
+const (
+	// MissingRequired means a required environment variable was not set.
+	MissingRequired ErrorKind = iota
+
+	// ConversionFailed means the environment variable could not be converted to its type.
+	ConversionFailed
+
+	// ValidationFailed means the resolved value was rejected by a validator.
+	ValidationFailed
+
+	// LookupFailed means the value could not be read from its source, such as a NAME_FILE path.
+	LookupFailed
+
+	// DefaultFailed means the function given to DefaultFunc returned an error.
+	DefaultFailed
+)
+
+// String returns a human readable name for the error kind.
+func (k ErrorKind) String() string {
+	switch k {
+	case MissingRequired:
+		return "missing required"
+	case ConversionFailed:
+		return "conversion failed"
+	case ValidationFailed:
+		return "validation failed"
+	case LookupFailed:
+		return "lookup failed"
+	case DefaultFailed:
+		return "default failed"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+// ParseError describes a single environment variable that Parse() could not process.
+// Parse() joins one ParseError per failing variable, so callers can inspect them with errors.As.
+//
+// Example:
+//
+//	var pe *env.ParseError
+//	if errors.As(err, &pe) && pe.Kind == env.MissingRequired {
+//		log.Fatalf("please set %s", pe.Name)
+//	}
+type ParseError struct {
+	Name     string    // The name of the environment variable, including any prefix.
+	VarType  string    // The data type of the environment variable.
+	RawValue string    // The raw value read from the environment, redacted for secrets.
+	Kind     ErrorKind // Why the variable could not be parsed.
+	Err      error     // The underlying cause.
+
+	// formatter renders the error instead of the default format, if not nil.
+	formatter func(name, varType, raw string, err error) string
+}
+
+// SetErrorFormatter sets a function that renders the message of each ParseError returned by Parse(), i.e. to
+// match the phrasing or language of existing logs. It receives the name of the variable including any prefix,
+// its type, its raw value, which is "****" for secrets, and the underlying cause. A nil formatter, the default,
+// keeps the "expected: NAME type: TYPE got: RAW: CAUSE" format.
+//
+// Example:
+//
+//	env.SetErrorFormatter(func(name, varType, raw string, err error) string {
+//		return fmt.Sprintf("config %s=%q (%s): %v", name, raw, varType, err)
+//	})
+func SetErrorFormatter(fn func(name, varType, raw string, err error) string) {
+	defaultRegistry.SetErrorFormatter(fn)
+}
+
+// SetErrorFormatter is like the package-level SetErrorFormatter but applies to the variables registered with r.
+func (r *Registry) SetErrorFormatter(fn func(name, varType, raw string, err error) string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errorFormatter = fn
+}
+
+// newParseError creates a ParseError for e, looked up as name, redacting the raw value if e is a secret.
+func newParseError(e envVar, name string, kind ErrorKind, err error) *ParseError {
+	// Never echo the raw value of a secret variable.
+	raw := *e.envValue
+	if e.secret && raw != "" {
+		err = redactedError{err, raw}
+		raw = redacted
+	}
+
+	return &ParseError{
+		Name:     name,
+		VarType:  e.varType,
+		RawValue: raw,
+		Kind:     kind,
+		Err:      err,
+	}
+}
+
+// Error formats the error as "expected: NAME type: TYPE got: RAW: CAUSE", unless a formatter was set
+// with SetErrorFormatter.
+func (e *ParseError) Error() string {
+	if e.formatter != nil {
+		return e.formatter(e.Name, e.VarType, e.RawValue, e.Err)
+	}
+
+	return fmt.Sprintf("expected: %s type: %s got: %s: %v", e.Name, e.VarType, e.RawValue, e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// redactedError hides the secret input quoted in the message of the error it wraps, such as the "hunter2"
+// in strconv.ParseInt: parsing "hunter2": invalid syntax. Only quoted strings that are part of the secret are
+// replaced, so the rest of the message stays readable and does not reveal which characters the secret holds.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e redactedError) Error() string {
+	msg := e.err.Error()
+
+	var b strings.Builder
+	for i := 0; i < len(msg); {
+		q, err := strconv.QuotedPrefix(msg[i:])
+		if msg[i] != '"' || err != nil {
+			b.WriteByte(msg[i])
+			i++
+			continue
+		}
+
+		if s, _ := strconv.Unquote(q); s != "" && strings.Contains(e.secret, s) {
+			b.WriteString(strconv.Quote(redacted))
+		} else {
+			b.WriteString(q)
+		}
+		i += len(q)
+	}
+
+	return b.String()
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
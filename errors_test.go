@@ -0,0 +1,74 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorMissingRequired(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	String("nic", true, "", "something")
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "nic", pe.Name)
+	assert.Equal(t, "string", pe.VarType)
+	assert.Equal(t, MissingRequired, pe.Kind)
+}
+
+func TestParseErrorConversionFailed(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a")
+	defer cleanup()
+
+	Int("nic", false, 0, "something")
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "nic", pe.Name)
+	assert.Equal(t, "integer", pe.VarType)
+	assert.Equal(t, "a", pe.RawValue)
+	assert.Equal(t, ConversionFailed, pe.Kind)
+	assert.Error(t, pe.Err)
+}
+
+func TestParseErrorRedactsSecret(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "not*base64")
+	defer cleanup()
+
+	Bytes("nic", false, nil, "something")
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "****", pe.RawValue)
+}
+
+func TestSetErrorFormatter(t *testing.T) {
+	Reset()
+	os.Unsetenv("HOST")
+	cleanup := setEnv("PORT", "abc")
+	defer cleanup()
+
+	Int("PORT", true, 0, "something")
+	String("HOST", true, "", "something")
+	SetErrorFormatter(func(name, varType, raw string, err error) string {
+		return fmt.Sprintf("config %s=%q (%s)", name, raw, varType)
+	})
+
+	err := Parse()
+	assert.EqualError(t, err, "config PORT=\"abc\" (integer)\nconfig HOST=\"\" (string)")
+
+	SetErrorFormatter(nil)
+	err = Parse()
+	assert.Contains(t, err.Error(), "expected: PORT type: integer got: abc")
+}
@@ -0,0 +1,79 @@
+package env
+
+import (
+	"flag"
+	"maps"
+	"strings"
+)
+
+// BindFlags registers a flag on fs for every variable registered so far, named after the variable in
+// lower case and using its help string as usage, i.e. BIND_PORT becomes -bind_port. Once fs has been
+// parsed, Parse() gives a flag that was set on the command line precedence over the environment, which
+// in turn takes precedence over the default. Flags for boolean variables may be given without a value.
+//
+// Example:
+//
+//	port := env.Int("BIND_PORT", false, 8080, "Port to listen on")
+//	env.BindFlags(flag.CommandLine)
+//	flag.Parse()
+//	err := env.Parse()
+func BindFlags(fs *flag.FlagSet) {
+	defaultRegistry.BindFlags(fs)
+}
+
+// BindFlags is like the package-level BindFlags but binds the variables registered with r.
+func (r *Registry) BindFlags(fs *flag.FlagSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Copy rather than modify the existing flags, since Unmarshal shares them with temporary registries.
+	flags := maps.Clone(r.flags)
+	if flags == nil {
+		flags = make(map[string]*flagValue)
+	}
+
+	for _, e := range r.envs {
+		f := &flagValue{isBool: e.varType == "boolean"}
+		fs.Var(f, strings.ToLower(e.name), e.help)
+		flags[e.name] = f
+	}
+
+	r.flags = flags
+}
+
+// flagValue is a flag.Value that records the raw value given on the command line.
+type flagValue struct {
+	value  string
+	set    bool // Whether the flag was given on the command line.
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+func (f *flagValue) Set(s string) error {
+	f.value = s
+	f.set = true
+
+	return nil
+}
+
+// IsBoolFlag allows the flags of boolean variables to be given without a value, as with flag.Bool.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// flagOf returns the raw value of the flag bound to e, and whether it was set on the command line.
+func (r *Registry) flagOf(e envVar) (string, bool) {
+	f, ok := r.flags[e.name]
+	if !ok || !f.set {
+		return "", false
+	}
+
+	return f.value, true
+}
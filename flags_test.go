@@ -0,0 +1,49 @@
+package env
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindFlags(t *testing.T) {
+	Reset()
+	cleanup := setEnv("PORT", "9090")
+	defer cleanup()
+	cleanup2 := setEnv("HOST", "example.com")
+	defer cleanup2()
+
+	port := Int("PORT", false, 8080, "Port to listen on")
+	host := String("HOST", false, "localhost", "Host to listen on")
+	debug := Bool("DEBUG", false, false, "Enable debugging")
+	timeout := Duration("TIMEOUT", false, time.Second, "Request timeout")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	BindFlags(fs)
+	assert.Equal(t, "Port to listen on", fs.Lookup("port").Usage)
+
+	assert.NoError(t, fs.Parse([]string{"-port", "7070", "-debug"}))
+	assert.NoError(t, Parse())
+
+	// Flags take precedence over the environment, which takes precedence over defaults.
+	assert.Equal(t, 7070, *port)
+	assert.Equal(t, true, *debug)
+	assert.Equal(t, "example.com", *host)
+	assert.Equal(t, time.Second, *timeout)
+}
+
+func TestBindFlagsInvalidValue(t *testing.T) {
+	Reset()
+
+	Int("PORT", false, 8080, "Port to listen on")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	BindFlags(fs)
+	assert.NoError(t, fs.Parse([]string{"-port", "abc"}))
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: PORT type: integer got: abc")
+}
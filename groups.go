@@ -0,0 +1,109 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// group is a constraint on how many of a set of variables may be set.
+type group struct {
+	names []string
+	kind  groupKind
+}
+
+// groupKind is the constraint a group places on its variables.
+type groupKind int
+
+const (
+	groupExclusive  groupKind = iota // At most one of the names may be set.
+	groupExactlyOne                  // Exactly one of the names must be set.
+	groupTogether                    // Either all or none of the names must be set.
+)
+
+// ExclusiveGroup declares that at most one of the named variables may be set. Parse() returns an
+// error naming the conflicting variables if more than one is set. The names need not be registered.
+//
+// Example:
+//
+//	env.ExclusiveGroup("DATABASE_URL", "DB_HOST")
+func ExclusiveGroup(names ...string) {
+	defaultRegistry.ExclusiveGroup(names...)
+}
+
+// ExclusiveGroup is like the package-level ExclusiveGroup but applies to the variables registered with r.
+func (r *Registry) ExclusiveGroup(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups = append(r.groups, group{names: names, kind: groupExclusive})
+}
+
+// ExactlyOneGroup is like ExclusiveGroup, but Parse() also returns an error if none of the named
+// variables are set.
+func ExactlyOneGroup(names ...string) {
+	defaultRegistry.ExactlyOneGroup(names...)
+}
+
+// ExactlyOneGroup is like the package-level ExactlyOneGroup but applies to the variables registered with r.
+func (r *Registry) ExactlyOneGroup(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups = append(r.groups, group{names: names, kind: groupExactlyOne})
+}
+
+// TogetherGroup declares that the named variables must be set together or not at all, i.e. the credentials
+// and bucket of an integration. Parse() returns an error naming the missing variables if some but not all
+// of them are set. If none are set, the group is satisfied, although variables that are individually
+// required are still reported.
+//
+// Example:
+//
+//	env.TogetherGroup("S3_KEY", "S3_SECRET", "S3_BUCKET")
+func TogetherGroup(names ...string) {
+	defaultRegistry.TogetherGroup(names...)
+}
+
+// TogetherGroup is like the package-level TogetherGroup but applies to the variables registered with r.
+func (r *Registry) TogetherGroup(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.groups = append(r.groups, group{names: names, kind: groupTogether})
+}
+
+// checkGroups returns an error for every group whose constraint is not met.
+func (r *Registry) checkGroups() []error {
+	errs := make([]error, 0)
+	for _, g := range r.groups {
+		set, unset := make([]string, 0), make([]string, 0)
+		for _, name := range g.names {
+			if r.rawValue(name) != "" {
+				set = append(set, r.prefix+name)
+			} else {
+				unset = append(unset, r.prefix+name)
+			}
+		}
+
+		switch {
+		case g.kind == groupTogether && len(set) > 0 && len(unset) > 0:
+			errs = append(errs, fmt.Errorf("%s should be set together, missing %s", r.groupNames(g), strings.Join(unset, ", ")))
+		case g.kind != groupTogether && len(set) > 1:
+			errs = append(errs, fmt.Errorf("only one of %s may be set, got %s", r.groupNames(g), strings.Join(set, ", ")))
+		case g.kind == groupExactlyOne && len(set) == 0:
+			errs = append(errs, fmt.Errorf("one of %s should be provided", r.groupNames(g)))
+		}
+	}
+
+	return errs
+}
+
+// groupNames returns the names in g, including any prefix, separated by commas.
+func (r *Registry) groupNames(g group) string {
+	names := make([]string, 0, len(g.names))
+	for _, name := range g.names {
+		names = append(names, r.prefix+name)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExclusiveGroup(t *testing.T) {
+	Reset()
+	cleanupURL := setEnv("DATABASE_URL", "postgres://localhost")
+	defer cleanupURL()
+	cleanupHost := setEnv("DB_HOST", "localhost")
+	defer cleanupHost()
+	os.Unsetenv("DB_SOCKET")
+
+	String("DATABASE_URL", false, "", "something")
+	ExclusiveGroup("DATABASE_URL", "DB_HOST", "DB_SOCKET")
+
+	assert.EqualError(t, Parse(), "only one of DATABASE_URL, DB_HOST, DB_SOCKET may be set, got DATABASE_URL, DB_HOST")
+}
+
+func TestExclusiveGroupNoneSet(t *testing.T) {
+	Reset()
+	os.Unsetenv("DATABASE_URL")
+	os.Unsetenv("DB_HOST")
+
+	String("DATABASE_URL", false, "default", "something")
+	ExclusiveGroup("DATABASE_URL", "DB_HOST")
+
+	assert.NoError(t, Parse())
+}
+
+func TestExactlyOneGroup(t *testing.T) {
+	Reset()
+	os.Unsetenv("DATABASE_URL")
+	os.Unsetenv("DB_HOST")
+
+	ExactlyOneGroup("DATABASE_URL", "DB_HOST")
+	assert.EqualError(t, Parse(), "one of DATABASE_URL, DB_HOST should be provided")
+
+	cleanup := setEnv("DB_HOST", "localhost")
+	defer cleanup()
+	assert.NoError(t, Parse())
+}
+
+func TestTogetherGroup(t *testing.T) {
+	Reset()
+	os.Unsetenv("S3_BUCKET")
+	cleanup := setEnv("S3_KEY", "key")
+	defer cleanup()
+	cleanup2 := setEnv("S3_SECRET", "secret")
+	defer cleanup2()
+
+	String("S3_KEY", false, "", "something")
+	String("S3_SECRET", false, "", "something", Secret())
+	String("S3_BUCKET", false, "", "something")
+	TogetherGroup("S3_KEY", "S3_SECRET", "S3_BUCKET")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "S3_KEY, S3_SECRET, S3_BUCKET should be set together, missing S3_BUCKET")
+
+	os.Unsetenv("S3_KEY")
+	os.Unsetenv("S3_SECRET")
+	assert.NoError(t, Parse())
+}
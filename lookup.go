@@ -0,0 +1,427 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EnableFileFallback makes Parse() read a variable from a file when it is not set directly. If NAME is
+// unset but NAME_FILE holds a path, the contents of that file with surrounding whitespace trimmed are
+// used as the value of NAME. If both are set, NAME takes precedence. An unreadable NAME_FILE causes
+// Parse() to return an error. This supports Docker secrets and similar sidecars that mount secrets as files.
+func EnableFileFallback() {
+	defaultRegistry.EnableFileFallback()
+}
+
+// EnableFileFallback is like the package-level EnableFileFallback but applies to the variables registered with r.
+func (r *Registry) EnableFileFallback() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fileFallback = true
+}
+
+// SetCaseInsensitive controls whether Parse() matches environment variable names regardless of case, so that
+// bind_port, BIND_PORT and Bind_Port all satisfy a registered BIND_PORT. An exact match always wins. Otherwise,
+// if several environment entries differ only by case, the one whose name sorts first byte-wise is used, i.e.
+// BIND_PORT before Bind_Port before bind_port. Names given to ParseFrom's lookup are always matched exactly.
+func SetCaseInsensitive(enabled bool) {
+	defaultRegistry.SetCaseInsensitive(enabled)
+}
+
+// SetCaseInsensitive is like the package-level SetCaseInsensitive but applies to the variables registered with r.
+func (r *Registry) SetCaseInsensitive(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.caseInsensitive = enabled
+}
+
+// SetTrimSpace controls whether Parse() trims surrounding whitespace, such as a trailing newline pasted into
+// a CI secret, from raw values before converting them. It is disabled by default, so values are used exactly
+// as found. Individual variables can override this setting with the TrimSpace option, i.e. a string whose
+// leading spaces are meaningful.
+func SetTrimSpace(enabled bool) {
+	defaultRegistry.SetTrimSpace(enabled)
+}
+
+// SetTrimSpace is like the package-level SetTrimSpace but applies to the variables registered with r.
+func (r *Registry) SetTrimSpace(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trimSpace = enabled
+}
+
+// SetExpand controls whether Parse() expands $VAR and ${VAR} references in raw values before converting
+// them, i.e. LOG_DIR=$HOME/logs, as a shell would. References are resolved against the environment, or
+// the lookup given to ParseFrom, without any prefix, and unset variables expand to "". A literal dollar
+// sign is written as $$. A value that expands to "" is treated as unset. It is disabled by default.
+func SetExpand(enabled bool) {
+	defaultRegistry.SetExpand(enabled)
+}
+
+// SetExpand is like the package-level SetExpand but applies to the variables registered with r.
+func (r *Registry) SetExpand(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expand = enabled
+}
+
+// ResolveSource is one of the places Parse() can read a value from, as given to SetResolveOrder.
+type ResolveSource int
+
+const (
+	ResolveFlag ResolveSource = iota // A command-line flag bound by BindFlags.
+	ResolveEnv                       // The process environment, including values set by LoadFile and LoadReader.
+	ResolveFile                      // The file named by NAME_FILE, if EnableFileFallback was called.
+	ResolveYAML                      // The values loaded by LoadYAML.
+)
+
+// defaultResolveOrder is the order sources are consulted in unless SetResolveOrder was called.
+var defaultResolveOrder = []ResolveSource{ResolveFlag, ResolveEnv, ResolveFile, ResolveYAML}
+
+// SetResolveOrder sets the order Parse() consults sources in for each variable, so that precedence is explicit
+// as sources multiply. The first source that provides a non-empty value wins, and the default is used if none
+// does. Sources left out of the order are not consulted at all. Calling it with no sources restores the default
+// order: flags, then the environment, then NAME_FILE, then YAML. Fallback variables are each looked up in every
+// source in turn, in the same order. An unknown source causes a panic.
+//
+// Example:
+//
+//	// Let a config file override the environment, but never read flags.
+//	env.SetResolveOrder(env.ResolveYAML, env.ResolveEnv)
+func SetResolveOrder(sources ...ResolveSource) {
+	defaultRegistry.SetResolveOrder(sources...)
+}
+
+// SetResolveOrder is like the package-level SetResolveOrder but applies to the variables registered with r.
+func (r *Registry) SetResolveOrder(sources ...ResolveSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range sources {
+		if s < ResolveFlag || s > ResolveYAML {
+			panic(fmt.Sprintf("env: unknown resolve source %d", s))
+		}
+	}
+
+	r.resolveOrder = nil
+	if len(sources) > 0 {
+		r.resolveOrder = slices.Clone(sources)
+	}
+}
+
+// lookup returns the value of e from the command line or environment, expanded if SetExpand is enabled,
+// or "" if it is not set, along with the source it was read from and the name that supplied it, as
+// returned by lookupRaw.
+func (r *Registry) lookup(e envVar) (string, string, string, error) {
+	v, source, name, err := r.lookupRaw(e)
+	if r.expand && err == nil {
+		v = os.Expand(v, func(name string) string {
+			// os.Expand passes "$" for "$$", which escapes a literal dollar sign.
+			if name == "$" {
+				return "$"
+			}
+			return r.getenv(name)
+		})
+	}
+
+	return v, source, name, err
+}
+
+// lookupRaw returns the raw value of e from the command line or environment, or "" if it is not set,
+// along with the source it was read from and the name, without any prefix, that supplied it. If e is
+// unset, its deprecated aliases and then the variables in its fallback chain are consulted in order. Each
+// name is looked up in every source in the resolve order before moving on to the next. Flags are only
+// bound to e itself.
+func (r *Registry) lookupRaw(e envVar) (string, string, string, error) {
+	chain, err := r.fallbackChain(e)
+	if err != nil {
+		return "", "", "", err
+	}
+	names := slices.Concat(chain[:1], e.aliases, chain[1:])
+
+	order := r.resolveOrder
+	if order == nil {
+		order = defaultResolveOrder
+	}
+	if e.indexed {
+		v, source, err := r.lookupIndexed(e, order)
+		return v, source, e.name, err
+	}
+
+	for i, name := range names {
+		for _, from := range order {
+			if from == ResolveFlag && i > 0 {
+				continue
+			}
+
+			v, source, err := r.lookupFrom(e, r.prefix+name, from)
+			if v != "" || err != nil {
+				return v, source, name, err
+			}
+		}
+	}
+
+	return "", "", "", nil
+}
+
+// lookupIndexed returns the values of NAME_0, NAME_1 and so on for the variable e registered with
+// IndexedStringSlice, up to the first one that is unset, joined into a single raw value, along with the
+// source the first of them was read from.
+func (r *Registry) lookupIndexed(e envVar, order []ResolveSource) (string, string, error) {
+	parts := make([]string, 0)
+	first := ""
+	for n := 0; ; n++ {
+		var v, source string
+		var err error
+		for _, from := range order {
+			if from == ResolveFlag {
+				continue
+			}
+
+			v, source, err = r.lookupFrom(e, r.envName(e)+"_"+strconv.Itoa(n), from)
+			if v != "" || err != nil {
+				break
+			}
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if v == "" {
+			break
+		}
+
+		if n == 0 {
+			first = source
+		}
+		parts = append(parts, quoteElement(v, ","))
+	}
+
+	return strings.Join(parts, ","), first, nil
+}
+
+// lookupFrom returns the raw value of the variable name from the source from, using the settings of e,
+// along with the source it was read from, as reported by Source.
+func (r *Registry) lookupFrom(e envVar, name string, from ResolveSource) (string, string, error) {
+	switch from {
+	case ResolveFlag:
+		if v, ok := r.flagOf(e); ok {
+			return v, sourceFlag, nil
+		}
+
+	case ResolveEnv:
+		v := r.getenv(name)
+		if r.shouldTrim(e) {
+			v = strings.TrimSpace(v)
+		}
+		// Values set by LoadFile and LoadReader count as coming from a file, unless they were changed since.
+		if loaded, ok := loadedValues.Load(name); ok && r.lookupEnv == nil {
+			if values := loaded.([]string); values[len(values)-1] == os.Getenv(name) {
+				if e.accumulate && len(values) > 1 {
+					v = e.joinRepeated(values)
+				}
+				return v, sourceFile, nil
+			}
+		}
+
+		return v, sourceEnv, nil
+
+	case ResolveFile:
+		// Read the value from the file named by NAME_FILE.
+		if path := r.getenv(name + "_FILE"); path != "" && r.fileFallback {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return "", "", err
+			}
+
+			return strings.TrimSpace(string(b)), sourceFile, nil
+		}
+
+	case ResolveYAML:
+		return r.yamlValues[name], sourceFile, nil
+	}
+
+	return "", "", nil
+}
+
+// warnIgnoredFile logs that NAME_FILE is ignored for each name, such as from with the prefix applied, that
+// supplied the value of e from the environment while its NAME_FILE variable is set too. It is called once per
+// variable by Parse() rather than by lookupFrom, which required conditions and groups consult again.
+func (r *Registry) warnIgnoredFile(e envVar, from string) {
+	if !r.fileFallback {
+		return
+	}
+
+	order := r.resolveOrder
+	if order == nil {
+		order = defaultResolveOrder
+	}
+	warn := func(name string, fromEnv bool) {
+		if fromEnv && r.getenv(name+"_FILE") != "" {
+			r.logf("env: %s_FILE is ignored because %s is set", name, name)
+		}
+	}
+
+	if !e.indexed {
+		_, fromEnv := r.suppliedBy(e, r.prefix+from, order, from == e.name)
+		warn(r.prefix+from, fromEnv)
+		return
+	}
+	for n := 0; ; n++ {
+		name := r.envName(e) + "_" + strconv.Itoa(n)
+		found, fromEnv := r.suppliedBy(e, name, order, false)
+		if !found {
+			return
+		}
+		warn(name, fromEnv)
+	}
+}
+
+// suppliedBy reports whether the variable name is set in any of the sources in order, using the settings of e,
+// and whether the environment is the first of them to supply it. The command line is only consulted if flag is
+// true.
+func (r *Registry) suppliedBy(e envVar, name string, order []ResolveSource, flag bool) (bool, bool) {
+	for _, from := range order {
+		if from == ResolveFlag && !flag {
+			continue
+		}
+
+		if v, _, err := r.lookupFrom(e, name, from); v != "" || err != nil {
+			return true, from == ResolveEnv
+		}
+	}
+
+	return false, false
+}
+
+// fallbackChain returns the name of e followed by the names of its fallback variables, following the
+// fallbacks of registered variables in turn. It returns an error if the fallbacks form a cycle.
+func (r *Registry) fallbackChain(e envVar) ([]string, error) {
+	chain := []string{e.name}
+	for next := e.fallback; next != ""; next = r.fallbackOf(next) {
+		if slices.Contains(chain, next) {
+			return nil, fmt.Errorf("circular fallback %s -> %s", strings.Join(chain, " -> "), next)
+		}
+		chain = append(chain, next)
+	}
+
+	return chain, nil
+}
+
+// fallbackOf returns the fallback of the registered variable name, or "" if it has none or is not registered.
+func (r *Registry) fallbackOf(name string) string {
+	for _, e := range r.envs {
+		if e.name == name {
+			return e.fallback
+		}
+	}
+
+	return ""
+}
+
+// getenv returns the value of the environment variable name, matching case-insensitively if enabled.
+func (r *Registry) getenv(name string) string {
+	if r.lookupEnv != nil {
+		// A custom lookup cannot be enumerated, so it is always matched exactly.
+		v, _ := r.lookupEnv(name)
+		return v
+	}
+
+	v, ok := os.LookupEnv(name)
+	if ok || !r.caseInsensitive {
+		return v
+	}
+
+	// Collect every case-insensitive match and pick the first in sorted order so the result is deterministic.
+	matches := make([]string, 0)
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if strings.EqualFold(k, name) {
+			matches = append(matches, k)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	slices.Sort(matches)
+	return os.Getenv(matches[0])
+}
+
+// isSet reports whether the environment variable name is set, even to an empty value, matching
+// case-insensitively if enabled.
+func (r *Registry) isSet(name string) bool {
+	if r.lookupEnv != nil {
+		_, ok := r.lookupEnv(name)
+		return ok
+	}
+
+	if _, ok := os.LookupEnv(name); ok || !r.caseInsensitive {
+		return ok
+	}
+
+	return slices.ContainsFunc(os.Environ(), func(kv string) bool {
+		k, _, _ := strings.Cut(kv, "=")
+		return strings.EqualFold(k, name)
+	})
+}
+
+// shouldTrim reports whether surrounding whitespace should be trimmed from the raw value of e.
+func (r *Registry) shouldTrim(e envVar) bool {
+	if e.trimSpace != nil {
+		return *e.trimSpace
+	}
+
+	return r.trimSpace
+}
+
+// isRequired reports whether e is required, along with an explanation to append to the error
+// message if it is only required because of a RequiredIf condition.
+func (r *Registry) isRequired(e envVar) (bool, string) {
+	if e.required {
+		return true, ""
+	}
+
+	for _, c := range e.requiredIf {
+		if r.resolveString(c.name) == c.value {
+			return true, fmt.Sprintf(" when %s is %q", r.prefix+c.name, c.value)
+		}
+	}
+
+	return false, ""
+}
+
+// resolveString returns the value of the variable name as a string, without converting it. A registered
+// variable that is unset resolves to its default as it would be written in the environment.
+func (r *Registry) resolveString(name string) string {
+	if v := r.rawValue(name); v != "" {
+		return v
+	}
+
+	for _, e := range r.envs {
+		if e.name == name {
+			return e.format(e.defaultValue)
+		}
+	}
+
+	return ""
+}
+
+// rawValue returns the unconverted value of the variable name from the environment, or "" if it
+// is unset. A registered variable is looked up with its own settings, such as its fallbacks.
+func (r *Registry) rawValue(name string) string {
+	for _, e := range r.envs {
+		if e.name == name {
+			v, _, _, _ := r.lookup(e)
+			return v
+		}
+	}
+
+	return r.getenv(r.prefix + name)
+}
@@ -0,0 +1,227 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFallback(t *testing.T) {
+	Reset()
+	EnableFileFallback()
+	os.Unsetenv("nic")
+	path := filepath.Join(t.TempDir(), "secret")
+	assert.NoError(t, os.WriteFile(path, []byte("from file\n"), 0o600))
+	cleanup := setEnv("nic_FILE", path)
+	defer cleanup()
+
+	n := String("nic", true, "", "something")
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "from file", *n)
+}
+
+func TestFileFallbackPrefersVariable(t *testing.T) {
+	Reset()
+	EnableFileFallback()
+	cleanup := setEnv("nic", "direct")
+	defer cleanup()
+	cleanupFile := setEnv("nic_FILE", "/does/not/exist")
+	defer cleanupFile()
+
+	var warnings []string
+	SetLogger(func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+	n := String("nic", true, "", "something")
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "direct", *n)
+	assert.Equal(t, []string{"env: nic_FILE is ignored because nic is set"}, warnings)
+
+	// A variable that a group or a required condition refers to is still only reported once.
+	cleanupOther := setEnv("other", "set")
+	defer cleanupOther()
+	warnings = nil
+	String("other", false, "", "something", RequiredIf("nic", "direct"))
+	TogetherGroup("nic", "other")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"env: nic_FILE is ignored because nic is set"}, warnings)
+}
+
+func TestFileFallbackDisabled(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+	cleanupFile := setEnv("nic_FILE", "/does/not/exist")
+	defer cleanupFile()
+
+	n := String("nic", false, "default", "something")
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "default", *n)
+}
+
+func TestFileFallbackUnreadable(t *testing.T) {
+	Reset()
+	EnableFileFallback()
+	os.Unsetenv("nic")
+	cleanupFile := setEnv("nic_FILE", "/does/not/exist")
+	defer cleanupFile()
+
+	String("nic", false, "", "something")
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, LookupFailed, pe.Kind)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	Reset()
+	SetCaseInsensitive(true)
+	os.Unsetenv("CI_NAME")
+	cleanupLower := setEnv("ci_name", "lower")
+	defer cleanupLower()
+	cleanupMixed := setEnv("Ci_Name", "mixed")
+	defer cleanupMixed()
+
+	n := String("CI_NAME", true, "", "something")
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "mixed", *n)
+}
+
+func TestCaseInsensitivePrefersExactMatch(t *testing.T) {
+	Reset()
+	SetCaseInsensitive(true)
+	cleanupExact := setEnv("CI_NAME", "exact")
+	defer cleanupExact()
+	cleanupLower := setEnv("Ci_Name", "mixed")
+	defer cleanupLower()
+
+	n := String("CI_NAME", true, "", "something")
+	Parse()
+
+	assert.Equal(t, "exact", *n)
+}
+
+func TestCaseSensitiveByDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("CI_NAME")
+	cleanupLower := setEnv("ci_name", "lower")
+	defer cleanupLower()
+
+	n := String("CI_NAME", false, "default", "something")
+	Parse()
+
+	assert.Equal(t, "default", *n)
+}
+
+func TestTrimSpace(t *testing.T) {
+	Reset()
+	SetTrimSpace(true)
+	cleanup := setEnv("nic", " 42\n")
+	defer cleanup()
+	cleanupString := setEnv("other", "  indented")
+	defer cleanupString()
+
+	n := Int("nic", true, 0, "something")
+	s := String("other", true, "", "something", TrimSpace(false))
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, *n)
+	assert.Equal(t, "  indented", *s)
+}
+
+func TestTrimSpaceDisabledByDefault(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", " 42\n")
+	defer cleanup()
+
+	Int("nic", true, 0, "something")
+	err := Parse()
+
+	assert.Error(t, err)
+}
+
+func TestTrimSpaceOption(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "true ")
+	defer cleanup()
+
+	n := Bool("nic", true, false, "something", TrimSpace(true))
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.True(t, *n)
+}
+
+func TestSetExpand(t *testing.T) {
+	Reset()
+	os.Unsetenv("EXPAND_UNSET")
+	cleanup := setEnv("EXPAND_HOME", "/home/app")
+	defer cleanup()
+	cleanup2 := setEnv("LOG_DIR", "$EXPAND_HOME/logs")
+	defer cleanup2()
+	cleanup3 := setEnv("PRICE", "$$5 ${EXPAND_HOME}")
+	defer cleanup3()
+	cleanup4 := setEnv("CACHE_DIR", "$EXPAND_UNSET")
+	defer cleanup4()
+
+	logDir := String("LOG_DIR", false, "", "something")
+	price := String("PRICE", false, "", "something")
+	cacheDir := String("CACHE_DIR", false, "/tmp", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "$EXPAND_HOME/logs", *logDir)
+
+	SetExpand(true)
+	assert.NoError(t, Parse())
+	assert.Equal(t, "/home/app/logs", *logDir)
+	assert.Equal(t, "$5 /home/app", *price)
+	assert.Equal(t, "/tmp", *cacheDir)
+}
+
+func TestSetResolveOrder(t *testing.T) {
+	Reset()
+	os.Unsetenv("DB_PORT")
+	cleanup := setEnv("LOG_LEVEL", "debug")
+	defer cleanup()
+
+	assert.NoError(t, LoadYAML(strings.NewReader("log-level: info\ndb-port: 5432\n")))
+	SetResolveOrder(ResolveYAML, ResolveEnv)
+
+	level := String("LOG_LEVEL", false, "", "something")
+	port := Int("DB_PORT", false, 0, "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "info", *level)
+	assert.Equal(t, 5432, *port)
+	assert.Equal(t, "file", Source("LOG_LEVEL"))
+
+	// Sources left out of the order are not consulted.
+	SetResolveOrder(ResolveEnv)
+	assert.NoError(t, Parse())
+	assert.Equal(t, "debug", *level)
+	assert.Equal(t, 0, *port)
+
+	// No sources restores the default order.
+	SetResolveOrder()
+	assert.NoError(t, Parse())
+	assert.Equal(t, "debug", *level)
+	assert.Equal(t, 5432, *port)
+
+	assert.Panics(t, func() { SetResolveOrder(ResolveSource(42)) })
+}
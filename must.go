@@ -0,0 +1,54 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// MustString returns the value of the environment variable name, panicking if it is unset or empty. Unlike
+// String, it reads the environment immediately and ignores the registration lifecycle: the variable is not
+// registered, so it is unaffected by SetPrefix and the other settings and is left out of Parse(), Help and
+// Usage. It is meant for short scripts and one-off tools.
+//
+// Example:
+//
+//	home := env.MustString("HOME")
+func MustString(name string) string {
+	v := os.Getenv(name)
+	if v == "" {
+		panic(fmt.Sprintf("env: %s should be provided", name))
+	}
+
+	return v
+}
+
+// MustInt is like MustString but converts the value to an int, also panicking if it is not an integer.
+func MustInt(name string) int {
+	n, err := strconv.ParseInt(MustString(name), 10, strconv.IntSize)
+	if err != nil {
+		panic(fmt.Sprintf("env: invalid integer for %s: %v", name, err))
+	}
+
+	return int(n)
+}
+
+// MustBool is like MustString but converts the value with strconv.ParseBool, also panicking if it is not a boolean.
+func MustBool(name string) bool {
+	b, err := strconv.ParseBool(MustString(name))
+	if err != nil {
+		panic(fmt.Sprintf("env: invalid boolean for %s: %v", name, err))
+	}
+
+	return b
+}
+
+// MustFloat64 is like MustString but converts the value to a float64, also panicking if it is not a number.
+func MustFloat64(name string) float64 {
+	f, err := strconv.ParseFloat(MustString(name), 64)
+	if err != nil {
+		panic(fmt.Sprintf("env: invalid float for %s: %v", name, err))
+	}
+
+	return f
+}
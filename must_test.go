@@ -0,0 +1,39 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMustReaders(t *testing.T) {
+	Reset()
+	cleanup := setEnv("MUST_STRING", "value")
+	defer cleanup()
+	cleanup2 := setEnv("MUST_INT", "42")
+	defer cleanup2()
+	cleanup3 := setEnv("MUST_BOOL", "true")
+	defer cleanup3()
+	cleanup4 := setEnv("MUST_FLOAT", "1.5")
+	defer cleanup4()
+
+	assert.Equal(t, "value", MustString("MUST_STRING"))
+	assert.Equal(t, 42, MustInt("MUST_INT"))
+	assert.Equal(t, true, MustBool("MUST_BOOL"))
+	assert.Equal(t, 1.5, MustFloat64("MUST_FLOAT"))
+
+	// Nothing is registered.
+	assert.Empty(t, Vars())
+}
+
+func TestMustReadersPanic(t *testing.T) {
+	os.Unsetenv("MUST_MISSING")
+	cleanup := setEnv("MUST_INT", "abc")
+	defer cleanup()
+
+	assert.PanicsWithValue(t, "env: MUST_MISSING should be provided", func() { MustString("MUST_MISSING") })
+	assert.Panics(t, func() { MustInt("MUST_INT") })
+	assert.Panics(t, func() { MustBool("MUST_INT") })
+	assert.Panics(t, func() { MustFloat64("MUST_INT") })
+}
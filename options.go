@@ -0,0 +1,632 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Option customises an environment variable when it is registered. Options are passed as the
+// trailing arguments of any constructor.
+//
+// Example:
+//
+//	port := env.Int("PORT", false, 8080, "HTTP server port", env.Validate(func(v interface{}) error {
+//		if p := v.(int); p < 8000 || p > 8999 {
+//			return fmt.Errorf("%d is outside the 8000-8999 range", p)
+//		}
+//		return nil
+//	}))
+type Option func(*envVar)
+
+// Validate adds a check that Parse() runs against the variable's resolved value, whether it came from
+// the environment or the default. The value is passed with the variable's own type, i.e. an int for
+// env.Int. An error returned by fn is reported by Parse() with the variable name attached. Validate may
+// be given more than once, and the checks run in order.
+//
+// For constructors taking a string default, such as env.String or env.Enum, a non-empty default of a
+// variable that is not required is also converted and checked with fn when the variable is registered,
+// and a default that fails causes a panic. Checks given by ValidateContext, which may do network or disk
+// work, only run during Parse().
+func Validate(fn func(interface{}) error) Option {
+	return func(e *envVar) {
+		ValidateContext(func(_ context.Context, v interface{}) error {
+			return fn(v)
+		})(e)
+		e.plainChecks = append(e.plainChecks, fn)
+	}
+}
+
+// ValidateContext is like Validate, but fn also receives the context given to ParseContext, or
+// context.Background() for Parse(), so that checks doing network or disk work can honour cancellation.
+//
+// Example:
+//
+//	dsn := env.String("DATABASE_URL", true, "", "Database connection string", env.ValidateContext(func(ctx context.Context, v interface{}) error {
+//		return ping(ctx, v.(string))
+//	}))
+func ValidateContext(fn func(ctx context.Context, v interface{}) error) Option {
+	return func(e *envVar) {
+		e.validators = append(e.validators, fn)
+	}
+}
+
+// TrimSpace overrides SetTrimSpace for a single variable, controlling whether surrounding whitespace
+// is trimmed from its raw value before conversion.
+//
+// Example:
+//
+//	env.SetTrimSpace(true)
+//	indent := env.String("INDENT", false, "  ", "Indentation prefix", env.TrimSpace(false))
+func TrimSpace(enabled bool) Option {
+	return func(e *envVar) {
+		e.trimSpace = &enabled
+	}
+}
+
+// Secret marks a variable as sensitive, such as an API key. Its raw value is never included in
+// Parse() errors, and its value and default are shown as "****" by Help, Usage, GetAll and the
+// generated documentation.
+func Secret() Option {
+	return func(e *envVar) {
+		e.secret = true
+	}
+}
+
+// Fallback makes Parse() consult the variable name when this variable is unset, before using the
+// static default. If name is itself registered with a Fallback, that is followed in turn. Parse()
+// returns an error if the fallbacks form a cycle.
+//
+// Example:
+//
+//	publicURL := env.String("PUBLIC_URL", true, "", "Public base URL")
+//	internalURL := env.String("INTERNAL_URL", false, "", "Internal base URL", env.Fallback("PUBLIC_URL"))
+func Fallback(name string) Option {
+	return func(e *envVar) {
+		e.fallback = name
+	}
+}
+
+// DeprecatedAlias makes Parse() read the variable from its old name when it was renamed and the new name is
+// unset, so that existing deployments keep working while they migrate. The new name takes precedence when both
+// are set. Each time the old name is used, a deprecation warning is written to the logger set by SetLogger, if
+// any. The prefix set by SetPrefix applies to name too. DeprecatedAlias may be given more than once, in which
+// case the aliases are consulted in order, before any Fallback.
+//
+// Example:
+//
+//	addr := env.String("LISTEN_ADDR", false, ":8080", "Listen address", env.DeprecatedAlias("BIND_ADDRESS"))
+func DeprecatedAlias(name string) Option {
+	return func(e *envVar) {
+		e.aliases = append(e.aliases, name)
+	}
+}
+
+// RequiredIf makes a variable required only when the variable name resolves to value. The value of name
+// is compared as a string, either as set in the environment or, if name is registered and unset, as its
+// default would be written. RequiredIf may be given more than once, in which case any matching condition
+// makes the variable required.
+//
+// Example:
+//
+//	tlsEnabled := env.Bool("TLS_ENABLED", false, false, "Serve over TLS")
+//	tlsCert := env.String("TLS_CERT", false, "", "TLS certificate path", env.RequiredIf("TLS_ENABLED", "true"))
+func RequiredIf(name, value string) Option {
+	return func(e *envVar) {
+		e.requiredIf = append(e.requiredIf, condition{name, value})
+	}
+}
+
+// DefaultFunc computes the default of a variable during Parse(), and only when the variable is unset, so that
+// defaults that are expensive or only known at runtime, such as one derived from the hostname, are not computed
+// at package initialisation. The returned string is converted like a value from the environment. An error from
+// fn is reported by Parse() with the variable name attached, and an empty result falls back to the static default.
+//
+// Example:
+//
+//	id := env.String("INSTANCE_ID", false, "", "Instance identifier", env.DefaultFunc(os.Hostname))
+func DefaultFunc(fn func() (string, error)) Option {
+	return func(e *envVar) {
+		e.defaultFunc = fn
+	}
+}
+
+// IgnoreCase makes StringSliceUnique treat elements that differ only by case as duplicates. Giving it to
+// any other constructor causes a panic.
+func IgnoreCase() Option {
+	return func(e *envVar) {
+		if e.ignoreCase == nil {
+			panic(fmt.Sprintf("env: IgnoreCase is not supported for %s of type %s", e.name, e.varType))
+		}
+		*e.ignoreCase = true
+	}
+}
+
+// Invert makes env.Bool or env.FlexBool store the logical negation of the value, so that a negative variable
+// such as DISABLE_CACHE can back an enableCache bool without scattering ! through the code. The default is
+// given in terms of the variable as it is written, before the negation, and is documented that way by Help
+// and the Write functions: a default of false for DISABLE_CACHE stores true. Validators see the negated value.
+// Giving it to any other constructor causes a panic.
+//
+// Example:
+//
+//	enableCache := env.Bool("DISABLE_CACHE", false, false, "Disable the response cache", env.Invert())
+func Invert() Option {
+	return func(e *envVar) {
+		if e.invert == nil {
+			panic(fmt.Sprintf("env: Invert is not supported for %s of type %s", e.name, e.varType))
+		}
+		*e.invert = true
+	}
+}
+
+// Accumulate makes env.StringSlice, env.StringSliceUnique, env.Map and env.MapMulti use every value of a key
+// that is repeated in a file loaded by LoadFile or LoadReader, rather than only the last, as some tools export
+// lists as repeated KEY= lines. For a slice each line becomes one element, and for a map each line holds one or
+// more entries. It has no effect on values set in the environment directly, which cannot be repeated. Giving it
+// to any other constructor causes a panic.
+//
+// Example .env file, with env.StringSlice("ORIGIN", false, nil, ",", "Allowed origins", env.Accumulate()):
+//
+//	ORIGIN=https://a.example.com
+//	ORIGIN=https://b.example.com
+func Accumulate() Option {
+	return func(e *envVar) {
+		if e.joinRepeated == nil {
+			panic(fmt.Sprintf("env: Accumulate is not supported for %s of type %s", e.name, e.varType))
+		}
+		e.accumulate = true
+	}
+}
+
+// NonEmpty makes Parse() reject a slice or map variable, such as env.StringSlice, that resolves to no elements,
+// i.e. a value of only delimiters like ",,". This applies to the default as well, so an optional variable
+// needs a non-empty default. Giving it to a variable that is not a slice or map causes a panic.
+//
+// Example:
+//
+//	brokers := env.StringSlice("KAFKA_BROKERS", true, nil, ",", "Kafka bootstrap servers", env.NonEmpty())
+func NonEmpty() Option {
+	return func(e *envVar) {
+		switch reflect.ValueOf(e.get()).Kind() {
+		case reflect.Slice, reflect.Map:
+		default:
+			panic(fmt.Sprintf("env: NonEmpty is not supported for %s of type %s", e.name, e.varType))
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			if reflect.ValueOf(v).Len() == 0 {
+				return errors.New("at least one element is expected")
+			}
+			return nil
+		})
+	}
+}
+
+// AllowEmpty makes a string variable that is set to an empty value count as provided, so that a required
+// variable is satisfied by NAME= but not by NAME being absent, and an optional variable set to NAME= is
+// left empty rather than given its default. Without it, empty values are treated as unset. Giving it to a
+// variable that does not hold a string causes a panic.
+//
+// Example:
+//
+//	suffix := env.String("HOSTNAME_SUFFIX", true, "", "Suffix appended to host names", env.AllowEmpty())
+func AllowEmpty() Option {
+	return func(e *envVar) {
+		if _, ok := e.get().(string); !ok {
+			panic(fmt.Sprintf("env: AllowEmpty is not supported for %s of type %s", e.name, e.varType))
+		}
+		e.allowEmpty = true
+	}
+}
+
+// KeepEscapes makes env.MultiLine keep literal \n and \r\n sequences rather than turning them into newlines.
+// Giving it to any other constructor causes a panic.
+func KeepEscapes() Option {
+	return func(e *envVar) {
+		if e.unescape == nil {
+			panic(fmt.Sprintf("env: KeepEscapes is not supported for %s of type %s", e.name, e.varType))
+		}
+		*e.unescape = false
+	}
+}
+
+// Range makes Parse() reject values of a numeric variable, such as env.Int, env.Int64 or env.Float64, that
+// fall outside min and max inclusive. The default is checked when the variable is registered, and a default
+// outside the range, or a variable that is not numeric, causes a panic. The default of a required variable
+// is never used, so it is not checked.
+//
+// Example:
+//
+//	workers := env.Int("WORKERS", false, 4, "Number of worker goroutines", env.Range(1, 64))
+func Range(min, max float64) Option {
+	check := func(v interface{}) error {
+		f, ok := toFloat(v)
+		if !ok {
+			return fmt.Errorf("range is not supported for %T", v)
+		}
+		if f < min || f > max {
+			return fmt.Errorf("must be between %v and %v, got %v", min, max, v)
+		}
+
+		return nil
+	}
+
+	return func(e *envVar) {
+		if _, ok := toFloat(e.get()); !ok {
+			panic(fmt.Sprintf("env: Range is not supported for %s of type %s", e.name, e.varType))
+		}
+		if !e.required {
+			if err := check(e.defaultValue); err != nil {
+				panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+			}
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(v)
+		})
+	}
+}
+
+// NonNegative makes Parse() reject values of a numeric variable, such as env.Int, env.Int64 or env.Float64,
+// that are below zero. As with Range, the default of a variable that is not required is checked when the
+// variable is registered, and a negative default, or a variable that is not numeric, causes a panic.
+//
+// Example:
+//
+//	retries := env.Int("RETRIES", false, 3, "Number of retries", env.NonNegative())
+func NonNegative() Option {
+	check := func(v interface{}) error {
+		if f, _ := toFloat(v); f < 0 {
+			return fmt.Errorf("must not be negative, got %v", v)
+		}
+
+		return nil
+	}
+
+	return func(e *envVar) {
+		if _, ok := toFloat(e.get()); !ok {
+			panic(fmt.Sprintf("env: NonNegative is not supported for %s of type %s", e.name, e.varType))
+		}
+		if !e.required {
+			if err := check(e.defaultValue); err != nil {
+				panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+			}
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(v)
+		})
+	}
+}
+
+// AllowUnderscores makes Parse() accept underscores between the digits of a numeric variable, such as
+// MAX_ROWS=1_000_000, as in Go's own numeric literals. The underscores are removed before the value is
+// converted. An underscore must sit between two digits of the variable's base, such as the base given to
+// env.IntBase, or directly after a 0x, 0o or 0b prefix where the base is detected, so leading, trailing or
+// doubled underscores, as in _1000, 1000_ or 1__000, and ones next to other characters, as in 1_e5, still cause
+// an error. Giving it to a variable that is not numeric causes a panic.
+//
+// Example:
+//
+//	maxRows := env.Int("MAX_ROWS", false, 10000, "Maximum rows per query", env.AllowUnderscores())
+func AllowUnderscores() Option {
+	return func(e *envVar) {
+		if _, ok := toFloat(e.get()); !ok {
+			panic(fmt.Sprintf("env: AllowUnderscores is not supported for %s of type %s", e.name, e.varType))
+		}
+
+		setValue := e.setValue
+		e.setValue = func(i interface{}, s string) error {
+			s, err := stripUnderscores(s, e.base)
+			if err != nil {
+				return err
+			}
+
+			return setValue(i, s)
+		}
+	}
+}
+
+// stripUnderscores removes the underscores separating the digits of s, written in base, returning an error if
+// any of them is not between two digits of that base, as Go allows. A base of 0 detects the base from a 0x, 0o or
+// 0b prefix, which may be followed directly by an underscore, with decimal otherwise.
+func stripUnderscores(s string, base int) (string, error) {
+	body := strings.TrimLeft(s, "+-")
+
+	prefix := 0
+	if base == 0 {
+		base = 10
+		if len(body) > 2 && body[0] == '0' {
+			switch body[1] {
+			case 'x', 'X':
+				base, prefix = 16, 2
+			case 'o', 'O':
+				base, prefix = 8, 2
+			case 'b', 'B':
+				base, prefix = 2, 2
+			}
+		}
+	}
+	isDigit := func(c byte) bool {
+		d := 36
+		switch {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c >= 'a' && c <= 'z':
+			d = int(c-'a') + 10
+		case c >= 'A' && c <= 'Z':
+			d = int(c-'A') + 10
+		}
+		return d < base
+	}
+
+	for i := 0; i < len(body); i++ {
+		if body[i] != '_' {
+			continue
+		}
+		before := (prefix > 0 && i == prefix) || (i > 0 && isDigit(body[i-1]))
+		after := i+1 < len(body) && isDigit(body[i+1])
+		if !before || !after {
+			return "", fmt.Errorf("misplaced underscore in %q", s)
+		}
+	}
+
+	return strings.ReplaceAll(s, "_", ""), nil
+}
+
+// Finite makes Parse() reject NaN and infinite values of an env.Float64 or env.Float32 variable, which
+// strconv.ParseFloat accepts but are almost never valid configuration. As with Range, the default of a
+// variable that is not required is checked when the variable is registered, and a default that is not finite,
+// or a variable that is not a float, causes a panic.
+//
+// Example:
+//
+//	ratio := env.Float64("SAMPLE_RATIO", false, 0.1, "Fraction of requests to trace", env.Finite())
+func Finite() Option {
+	check := func(v interface{}) error {
+		if f, _ := toFloat(v); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("must be a finite number, got %v", v)
+		}
+
+		return nil
+	}
+
+	return func(e *envVar) {
+		switch e.get().(type) {
+		case float32, float64:
+		default:
+			panic(fmt.Sprintf("env: Finite is not supported for %s of type %s", e.name, e.varType))
+		}
+		if !e.required {
+			if err := check(e.defaultValue); err != nil {
+				panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+			}
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(v)
+		})
+	}
+}
+
+// Length makes Parse() reject values of a string variable that are shorter than min or longer than max
+// characters, where a max of 0 or less means no upper bound. An empty value, such as that of an unset
+// optional variable, is not checked. A non-empty default outside the bounds, or a variable that does not
+// hold a string, causes a panic when the variable is registered.
+//
+// Example:
+//
+//	token := env.String("API_TOKEN", true, "", "API token", env.Secret(), env.Length(32, 64))
+func Length(min, max int) Option {
+	check := func(v interface{}) error {
+		s, _ := v.(string)
+		n := utf8.RuneCountInString(s)
+		switch {
+		case n == 0:
+			return nil
+		case max <= 0 && n < min:
+			return fmt.Errorf("length must be at least %d, got %d", min, n)
+		case max > 0 && (n < min || n > max):
+			return fmt.Errorf("length must be between %d and %d, got %d", min, max, n)
+		}
+
+		return nil
+	}
+
+	return func(e *envVar) {
+		if _, ok := e.get().(string); !ok {
+			panic(fmt.Sprintf("env: Length is not supported for %s of type %s", e.name, e.varType))
+		}
+		if err := check(e.defaultValue); err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(v)
+		})
+	}
+}
+
+// DefaultUnit makes env.Duration interpret a value without a unit suffix, such as TIMEOUT=30, in unit rather
+// than rejecting it, to ease migrating from integer seconds. Values with a unit, such as 30s or 1m30s, parse as
+// before. Giving it to any other constructor causes a panic.
+//
+// Example:
+//
+//	timeout := env.Duration("TIMEOUT", false, 30*time.Second, "Request timeout", env.DefaultUnit(time.Second))
+func DefaultUnit(unit time.Duration) Option {
+	return func(e *envVar) {
+		if e.defaultUnit == nil {
+			panic(fmt.Sprintf("env: DefaultUnit is not supported for %s of type %s", e.name, e.varType))
+		}
+		*e.defaultUnit = unit
+	}
+}
+
+// MinDuration makes Parse() reject values of a time.Duration variable shorter than min, i.e. to catch an
+// accidental RETRY_INTERVAL=0 busy loop. A min of 0 means no lower bound. As with Range, the default of a
+// variable that is not required is checked when the variable is registered, and a default below min, or a
+// variable that is not a time.Duration, causes a panic.
+//
+// Example:
+//
+//	interval := env.Duration("RETRY_INTERVAL", false, time.Second, "Delay between retries", env.MinDuration(100*time.Millisecond))
+func MinDuration(min time.Duration) Option {
+	return durationBound("MinDuration", func(d time.Duration) error {
+		if min != 0 && d < min {
+			return fmt.Errorf("must be at least %s, got %s", min, d)
+		}
+		return nil
+	})
+}
+
+// MaxDuration is like MinDuration but rejects values longer than max. A max of 0 means no upper bound.
+func MaxDuration(max time.Duration) Option {
+	return durationBound("MaxDuration", func(d time.Duration) error {
+		if max != 0 && d > max {
+			return fmt.Errorf("must be at most %s, got %s", max, d)
+		}
+		return nil
+	})
+}
+
+// durationBound returns the Option named option, which adds check as a validator of a time.Duration variable.
+func durationBound(option string, check func(time.Duration) error) Option {
+	return func(e *envVar) {
+		if _, ok := e.get().(time.Duration); !ok {
+			panic(fmt.Sprintf("env: %s is not supported for %s of type %s", option, e.name, e.varType))
+		}
+		if !e.required {
+			if err := check(e.defaultValue.(time.Duration)); err != nil {
+				panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+			}
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(v.(time.Duration))
+		})
+	}
+}
+
+// checkDefault converts the string default def of e as a value from the environment would be, then runs the
+// checks given by Validate against the value setDefault assigns, which is the form Parse() uses.
+func (e envVar) checkDefault(def string) error {
+	typ := reflect.TypeOf(e.value).Elem()
+	if err := e.setValue(reflect.New(typ).Interface(), def); err != nil {
+		return err
+	}
+
+	scratch := reflect.New(typ).Interface()
+	e.setDefault(scratch, def)
+	for _, fn := range e.plainChecks {
+		if err := fn(reflect.ValueOf(scratch).Elem().Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toFloat converts v to a float64 if it is of a numeric kind.
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// MaxLen makes Parse() reject values of a slice variable, such as env.StringSlice or env.IntSlice, with more
+// than n elements, to guard against pathologically large configurations. As with Range, the default of a
+// variable that is not required is checked when the variable is registered, and a default that is too long, or
+// a variable that is not a slice, causes a panic.
+//
+// Example:
+//
+//	origins := env.StringSlice("CORS_ORIGINS", false, nil, ",", "Allowed origins", env.MaxLen(100))
+func MaxLen(n int) Option {
+	return sliceBound("MaxLen", func(l int) error {
+		if l > n {
+			return fmt.Errorf("must have at most %d elements, got %d", n, l)
+		}
+		return nil
+	})
+}
+
+// MinLen is like MaxLen but rejects values with fewer than n elements, i.e. MinLen(1) to require at least one.
+func MinLen(n int) Option {
+	return sliceBound("MinLen", func(l int) error {
+		if l < n {
+			return fmt.Errorf("must have at least %d elements, got %d", n, l)
+		}
+		return nil
+	})
+}
+
+// sliceBound returns the Option named option, which adds check of the element count as a validator of a slice
+// variable.
+func sliceBound(option string, check func(int) error) Option {
+	return func(e *envVar) {
+		if reflect.ValueOf(e.get()).Kind() != reflect.Slice {
+			panic(fmt.Sprintf("env: %s is not supported for %s of type %s", option, e.name, e.varType))
+		}
+		if !e.required {
+			if err := check(reflect.ValueOf(e.defaultValue).Len()); err != nil {
+				panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+			}
+		}
+
+		e.validators = append(e.validators, func(_ context.Context, v interface{}) error {
+			return check(reflect.ValueOf(v).Len())
+		})
+	}
+}
+
+// register applies opts to e and appends it to the variables registered with r.
+func (r *Registry) register(e envVar, opts []Option) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.source = new(string)
+	for _, opt := range opts {
+		opt(&e)
+	}
+
+	// Convert a string default the way a value from the environment would be and check it, so that a bad
+	// default fails fast rather than at the first Parse(). The default of a required variable is never used.
+	if def, ok := e.defaultValue.(string); ok && def != "" && !e.required {
+		if err := e.checkDefault(def); err != nil {
+			panic(fmt.Sprintf("env: invalid default value for %s: %v", e.name, err))
+		}
+	}
+
+	r.envs = append(r.envs, e)
+}
+
+// get returns the variable's current value, dereferenced from its pointer.
+func (e envVar) get() interface{} {
+	return reflect.ValueOf(e.value).Elem().Interface()
+}
+
+// validate runs the variable's validators against its current value, stopping at the first error.
+func (e envVar) validate(ctx context.Context) error {
+	for _, fn := range e.validators {
+		if err := fn(ctx, e.get()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
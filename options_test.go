@@ -0,0 +1,670 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func portRange(v interface{}) error {
+	if p := v.(int); p < 8000 || p > 8999 {
+		return fmt.Errorf("%d is outside the 8000-8999 range", p)
+	}
+
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "8080")
+	defer cleanup()
+
+	n := Int("nic", false, 8000, "something", Validate(portRange))
+	err := Parse()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, *n)
+}
+
+func TestValidateError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "9090")
+	defer cleanup()
+
+	Int("nic", false, 8000, "something", Validate(portRange))
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, ValidationFailed, pe.Kind)
+	assert.Contains(t, err.Error(), "expected: nic type: integer got: 9090: 9090 is outside the 8000-8999 range")
+}
+
+func TestValidateDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("nic")
+
+	Int("nic", false, 80, "something", Validate(portRange))
+	err := Parse()
+
+	assert.ErrorContains(t, err, "80 is outside the 8000-8999 range")
+}
+
+func TestSecret(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "hunter2")
+	defer cleanup()
+
+	n := String("nic", false, "changeme", "something", Secret())
+	Int("other", false, 1, "something", Secret())
+	assert.NoError(t, Parse())
+
+	var usage strings.Builder
+	Usage(&usage)
+
+	assert.Equal(t, "hunter2", *n)
+	assert.Equal(t, map[string]interface{}{"nic": "****", "other": "****"}, GetAll())
+	assert.Contains(t, Help(), "nic type: string default: '****'")
+	assert.NotContains(t, usage.String(), "changeme")
+	assert.Contains(t, usage.String(), "'****'")
+}
+
+func TestSecretError(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "hunter2")
+	defer cleanup()
+
+	Int("nic", false, 0, "something", Secret())
+	err := Parse()
+
+	assert.Contains(t, err.Error(), "expected: nic type: integer got: ****")
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestSecretErrorShortValue(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "a")
+	defer cleanup()
+
+	Int("nic", false, 0, "something", Secret())
+	err := Parse()
+
+	assert.EqualError(t, err, `expected: nic type: integer got: ****: strconv.ParseInt: parsing "****": invalid syntax`)
+}
+
+func TestFallback(t *testing.T) {
+	Reset()
+	os.Unsetenv("INTERNAL_URL")
+	os.Unsetenv("LOCAL_URL")
+	cleanup := setEnv("PUBLIC_URL", "https://example.com")
+	defer cleanup()
+
+	internal := String("INTERNAL_URL", false, "default", "something", Fallback("PUBLIC_URL"))
+	local := String("LOCAL_URL", false, "default", "something", Fallback("INTERNAL_URL"))
+	assert.NoError(t, Parse())
+
+	assert.Equal(t, "https://example.com", *internal)
+	assert.Equal(t, "https://example.com", *local)
+}
+
+func TestFallbackPrefersOwnName(t *testing.T) {
+	Reset()
+	cleanup := setEnv("INTERNAL_URL", "http://internal")
+	defer cleanup()
+	cleanupPublic := setEnv("PUBLIC_URL", "https://example.com")
+	defer cleanupPublic()
+
+	internal := String("INTERNAL_URL", false, "default", "something", Fallback("PUBLIC_URL"))
+	assert.NoError(t, Parse())
+
+	assert.Equal(t, "http://internal", *internal)
+}
+
+func TestFallbackDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("INTERNAL_URL")
+	os.Unsetenv("PUBLIC_URL")
+
+	internal := String("INTERNAL_URL", false, "default", "something", Fallback("PUBLIC_URL"))
+	assert.NoError(t, Parse())
+
+	assert.Equal(t, "default", *internal)
+}
+
+func TestFallbackCycle(t *testing.T) {
+	Reset()
+	os.Unsetenv("A")
+	os.Unsetenv("B")
+
+	String("A", false, "", "something", Fallback("B"))
+	String("B", false, "", "something", Fallback("A"))
+	err := Parse()
+
+	assert.ErrorContains(t, err, "circular fallback A -> B -> A")
+	assert.ErrorContains(t, err, "circular fallback B -> A -> B")
+}
+
+func TestRequiredIf(t *testing.T) {
+	Reset()
+	os.Unsetenv("TLS_CERT")
+	cleanup := setEnv("TLS_ENABLED", "true")
+	defer cleanup()
+
+	Bool("TLS_ENABLED", false, false, "something")
+	String("TLS_CERT", false, "", "something", RequiredIf("TLS_ENABLED", "true"))
+	err := Parse()
+
+	var pe *ParseError
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, MissingRequired, pe.Kind)
+	assert.ErrorContains(t, err, `TLS_CERT should be provided when TLS_ENABLED is "true"`)
+}
+
+func TestRequiredIfNotMet(t *testing.T) {
+	Reset()
+	os.Unsetenv("TLS_CERT")
+	os.Unsetenv("TLS_ENABLED")
+
+	String("TLS_CERT", false, "", "something", RequiredIf("TLS_ENABLED", "true"))
+	Bool("TLS_ENABLED", false, false, "something")
+
+	assert.NoError(t, Parse())
+}
+
+func TestRequiredIfDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("TLS_CERT")
+	os.Unsetenv("TLS_ENABLED")
+
+	String("TLS_CERT", false, "", "something", RequiredIf("TLS_ENABLED", "true"))
+	Bool("TLS_ENABLED", false, true, "something")
+
+	assert.ErrorContains(t, Parse(), "TLS_CERT should be provided")
+}
+
+func TestRange(t *testing.T) {
+	Reset()
+	cleanup := setEnv("WORKERS", "8")
+	defer cleanup()
+	cleanup2 := setEnv("RATIO", "1.5")
+	defer cleanup2()
+
+	workers := Int("WORKERS", false, 4, "something", Range(1, 64))
+	limit := Int64("LIMIT", false, 100, "something", Range(1, 1000))
+	Float64("RATIO", false, 0.5, "something", Range(0, 1))
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: RATIO type: float got: 1.5: must be between 0 and 1, got 1.5")
+	assert.Equal(t, 8, *workers)
+	assert.Equal(t, int64(100), *limit)
+}
+
+func TestRangeInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { Int("WORKERS", false, 0, "something", Range(1, 64)) })
+	assert.Panics(t, func() { String("NAME", false, "", "something", Range(1, 64)) })
+	assert.NotPanics(t, func() { Int("WORKERS", true, 0, "something", Range(1, 64)) })
+}
+
+func TestDefaultFunc(t *testing.T) {
+	Reset()
+	os.Unsetenv("INSTANCE_ID")
+	os.Unsetenv("WORKERS")
+	cleanup := setEnv("REGION", "eu-west-1")
+	defer cleanup()
+
+	calls := 0
+	id := String("INSTANCE_ID", false, "", "something", DefaultFunc(func() (string, error) {
+		calls++
+		return "host-1", nil
+	}))
+	workers := Int("WORKERS", false, 4, "something", DefaultFunc(func() (string, error) {
+		return "", nil
+	}))
+	region := String("REGION", false, "", "something", DefaultFunc(func() (string, error) {
+		calls++
+		return "us-east-1", nil
+	}))
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "host-1", *id)
+	assert.Equal(t, 4, *workers)
+	assert.Equal(t, "eu-west-1", *region)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDefaultFuncError(t *testing.T) {
+	Reset()
+	os.Unsetenv("INSTANCE_ID")
+
+	String("INSTANCE_ID", false, "", "something", DefaultFunc(func() (string, error) {
+		return "", errors.New("no hostname")
+	}))
+
+	err := Parse()
+	var pe *ParseError
+	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, DefaultFailed, pe.Kind)
+	assert.Contains(t, err.Error(), "expected: INSTANCE_ID type: string got: : no hostname")
+}
+
+func TestAllowUnderscores(t *testing.T) {
+	Reset()
+	cleanup := setEnv("MAX_ROWS", "1_000_000")
+	defer cleanup()
+	cleanup2 := setEnv("LIMIT", "1__000")
+	defer cleanup2()
+	cleanup3 := setEnv("RATIO", "0.000_5")
+	defer cleanup3()
+	cleanup4 := setEnv("RETRIES", "1_000")
+	defer cleanup4()
+
+	maxRows := Int("MAX_ROWS", true, 0, "something", AllowUnderscores())
+	Int64("LIMIT", true, 0, "something", AllowUnderscores())
+	ratio := Float64("RATIO", true, 0, "something", AllowUnderscores())
+	Int("RETRIES", true, 0, "something")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `expected: LIMIT type: int64 got: 1__000: misplaced underscore in "1__000"`)
+	assert.Contains(t, err.Error(), "expected: RETRIES type: integer got: 1_000")
+	assert.Equal(t, 1000000, *maxRows)
+	assert.Equal(t, 0.0005, *ratio)
+	assert.Panics(t, func() { String("NAME", false, "", "something", AllowUnderscores()) })
+
+	// The digits of an explicit base are taken from that base, so hex letters need no 0x prefix.
+	Reset()
+	cleanup5 := setEnv("MASK", "dead_beef")
+	defer cleanup5()
+	mask := IntBase("MASK", true, 0, 16, "something", AllowUnderscores())
+	assert.NoError(t, Parse())
+	assert.Equal(t, 0xdeadbeef, *mask)
+}
+
+func TestStripUnderscores(t *testing.T) {
+	for _, s := range []string{"1_e5", "_1", "1_", "1__0", "1_.5", "-_1", "0x_", "0_x1", "dead_beef", "0b_1_2"} {
+		_, err := stripUnderscores(s, 0)
+		assert.Error(t, err, s)
+	}
+	_, err := stripUnderscores("dead_beef_g", 16)
+	assert.Error(t, err)
+
+	got, err := stripUnderscores("dead_beef", 16)
+	assert.NoError(t, err)
+	assert.Equal(t, "deadbeef", got)
+
+	for s, want := range map[string]string{
+		"1_000_000":    "1000000",
+		"-1_000":       "-1000",
+		"0.000_5":      "0.0005",
+		"0x_dead_beef": "0xdeadbeef",
+		"0b_1010_0101": "0b10100101",
+		"0o_7_7":       "0o77",
+	} {
+		got, err := stripUnderscores(s, 0)
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+}
+
+func TestFinite(t *testing.T) {
+	Reset()
+	cleanup := setEnv("SAMPLE_RATIO", "NaN")
+	defer cleanup()
+	cleanup2 := setEnv("SCALE", "-Inf")
+	defer cleanup2()
+	cleanup3 := setEnv("WEIGHT", "0.5")
+	defer cleanup3()
+
+	Float64("SAMPLE_RATIO", true, 0, "something", Finite())
+	Float32("SCALE", true, 0, "something", Finite())
+	weight := Float64("WEIGHT", true, 0, "something", Finite())
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: SAMPLE_RATIO type: float got: NaN: must be a finite number, got NaN")
+	assert.Contains(t, err.Error(), "got: -Inf: must be a finite number, got -Inf")
+	assert.NotContains(t, err.Error(), "WEIGHT")
+	assert.Equal(t, 0.5, *weight)
+}
+
+func TestFiniteInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { Float64("SAMPLE_RATIO", false, math.Inf(1), "something", Finite()) })
+	assert.Panics(t, func() { Int("WORKERS", false, 1, "something", Finite()) })
+}
+
+func TestLength(t *testing.T) {
+	Reset()
+	os.Unsetenv("OPTIONAL_TOKEN")
+	cleanup := setEnv("API_TOKEN", "short")
+	defer cleanup()
+	cleanup2 := setEnv("NAME", "héllo")
+	defer cleanup2()
+	cleanup3 := setEnv("BIO", "x")
+	defer cleanup3()
+
+	String("API_TOKEN", true, "", "something", Secret(), Length(8, 64))
+	name := String("NAME", true, "", "something", Length(1, 5))
+	String("BIO", true, "", "something", Length(2, 0))
+	String("OPTIONAL_TOKEN", false, "", "something", Length(8, 64))
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: API_TOKEN type: string got: ****: length must be between 8 and 64, got 5")
+	assert.Contains(t, err.Error(), "expected: BIO type: string got: x: length must be at least 2, got 1")
+	assert.NotContains(t, err.Error(), "NAME")
+	assert.NotContains(t, err.Error(), "OPTIONAL_TOKEN")
+	assert.Equal(t, "héllo", *name)
+}
+
+func TestLengthInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { String("NAME", false, "toolong", "something", Length(1, 5)) })
+	assert.Panics(t, func() { Int("PORT", false, 0, "something", Length(1, 5)) })
+}
+
+func TestMaxLenMinLen(t *testing.T) {
+	Reset()
+	cleanup := setEnv("CORS_ORIGINS", "a,b,c")
+	defer cleanup()
+	cleanup2 := setEnv("WORKER_IDS", "1,2")
+	defer cleanup2()
+	cleanup3 := setEnv("TAGS", "a")
+	defer cleanup3()
+
+	StringSlice("CORS_ORIGINS", true, nil, ",", "something", MaxLen(2))
+	ids := IntSlice("WORKER_IDS", true, nil, ",", "something", MinLen(1), MaxLen(2))
+	StringSlice("TAGS", true, nil, ",", "something", MinLen(2))
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: CORS_ORIGINS type: []string got: a,b,c: must have at most 2 elements, got 3")
+	assert.Contains(t, err.Error(), "expected: TAGS type: []string got: a: must have at least 2 elements, got 1")
+	assert.NotContains(t, err.Error(), "WORKER_IDS")
+	assert.Equal(t, []int{1, 2}, *ids)
+}
+
+func TestMaxLenInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { StringSlice("CORS_ORIGINS", false, []string{"a", "b"}, ",", "something", MaxLen(1)) })
+	assert.Panics(t, func() { StringSlice("TAGS", false, nil, ",", "something", MinLen(1)) })
+	assert.Panics(t, func() { String("NAME", false, "", "something", MaxLen(1)) })
+}
+
+func TestValidateContext(t *testing.T) {
+	Reset()
+	cleanup := setEnv("DATABASE_URL", "postgres://db")
+	defer cleanup()
+
+	type key struct{}
+	var got interface{}
+	String("DATABASE_URL", true, "", "something", ValidateContext(func(ctx context.Context, v interface{}) error {
+		got = ctx.Value(key{})
+		return nil
+	}))
+
+	assert.NoError(t, ParseContext(context.WithValue(context.Background(), key{}, "value")))
+	assert.Equal(t, "value", got)
+}
+
+func TestParseContextCancelled(t *testing.T) {
+	Reset()
+	cleanup := setEnv("DATABASE_URL", "postgres://db")
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	String("DATABASE_URL", true, "", "something", ValidateContext(func(ctx context.Context, v interface{}) error {
+		calls++
+		cancel()
+		return ctx.Err()
+	}))
+	String("CACHE_URL", false, "", "something", ValidateContext(func(ctx context.Context, v interface{}) error {
+		calls++
+		return nil
+	}))
+
+	err := ParseContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDurationBounds(t *testing.T) {
+	Reset()
+	cleanup := setEnv("RETRY_INTERVAL", "0s")
+	defer cleanup()
+	cleanup2 := setEnv("TIMEOUT", "1000h")
+	defer cleanup2()
+	cleanup3 := setEnv("POLL", "5s")
+	defer cleanup3()
+
+	Duration("RETRY_INTERVAL", false, time.Second, "something", MinDuration(100*time.Millisecond))
+	Duration("TIMEOUT", false, time.Minute, "something", MinDuration(time.Second), MaxDuration(time.Hour))
+	poll := Duration("POLL", false, time.Second, "something", MinDuration(0), MaxDuration(time.Minute))
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: RETRY_INTERVAL type: duration got: 0s: must be at least 100ms, got 0s")
+	assert.Contains(t, err.Error(), "expected: TIMEOUT type: duration got: 1000h: must be at most 1h0m0s, got 1000h0m0s")
+	assert.Equal(t, 5*time.Second, *poll)
+}
+
+func TestDurationBoundsInvalidDefault(t *testing.T) {
+	Reset()
+
+	assert.Panics(t, func() { Duration("TIMEOUT", false, 0, "something", MinDuration(time.Second)) })
+	assert.Panics(t, func() { Int("TIMEOUT", false, 0, "something", MaxDuration(time.Second)) })
+	assert.NotPanics(t, func() { Duration("TIMEOUT", true, 0, "something", MinDuration(time.Second)) })
+}
+
+func TestNonEmpty(t *testing.T) {
+	Reset()
+	os.Unsetenv("TOPICS")
+	cleanup := setEnv("BROKERS", " , ,")
+	defer cleanup()
+	cleanup2 := setEnv("LABELS", "a=1")
+	defer cleanup2()
+
+	StringSlice("BROKERS", true, nil, ",", "something", NonEmpty())
+	labels := Map("LABELS", true, nil, ",", "=", "something", NonEmpty())
+	topics := StringSlice("TOPICS", false, []string{"events"}, ",", "something", NonEmpty())
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: BROKERS type: []string got:  , ,: at least one element is expected")
+	assert.Equal(t, map[string]string{"a": "1"}, *labels)
+	assert.Equal(t, []string{"events"}, *topics)
+	assert.Panics(t, func() { String("NAME", false, "", "something", NonEmpty()) })
+}
+
+func TestNonNegative(t *testing.T) {
+	Reset()
+	cleanup := setEnv("RETRIES", "-1")
+	defer cleanup()
+	cleanup2 := setEnv("RATE", "0")
+	defer cleanup2()
+
+	Int("RETRIES", false, 3, "something", NonNegative())
+	rate := Float64("RATE", false, 1, "something", NonNegative())
+	limit := Int64("LIMIT", false, 10, "something", NonNegative())
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected: RETRIES type: integer got: -1: must not be negative, got -1")
+	assert.Equal(t, float64(0), *rate)
+	assert.Equal(t, int64(10), *limit)
+	assert.Panics(t, func() { Int64("LIMIT", false, -5, "something", NonNegative()) })
+	assert.Panics(t, func() { String("NAME", false, "", "something", NonNegative()) })
+}
+
+func TestAllowEmpty(t *testing.T) {
+	Reset()
+	os.Unsetenv("MISSING")
+	cleanup := setEnv("SUFFIX", "")
+	defer cleanup()
+
+	suffix := String("SUFFIX", true, "", "something", AllowEmpty())
+	String("MISSING", true, "", "something", AllowEmpty())
+	optional := String("SUFFIX", false, "default", "something", AllowEmpty())
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "MISSING should be provided")
+	assert.NotContains(t, err.Error(), "SUFFIX")
+	assert.Equal(t, "", *suffix)
+	assert.Equal(t, "", *optional)
+	assert.Equal(t, "env", Source("SUFFIX"))
+
+	Reset()
+	String("SUFFIX", true, "", "something")
+	assert.ErrorContains(t, Parse(), "SUFFIX should be provided")
+	assert.Panics(t, func() { Int("PORT", true, 0, "something", AllowEmpty()) })
+}
+
+func TestDefaultUnit(t *testing.T) {
+	Reset()
+	cleanup := setEnv("TIMEOUT", "30")
+	defer cleanup()
+	cleanup2 := setEnv("INTERVAL", "1.5")
+	defer cleanup2()
+	cleanup3 := setEnv("DELAY", "250ms")
+	defer cleanup3()
+
+	timeout := Duration("TIMEOUT", true, 0, "something", DefaultUnit(time.Second))
+	interval := Duration("INTERVAL", true, 0, "something", DefaultUnit(time.Minute))
+	delay := Duration("DELAY", true, 0, "something", DefaultUnit(time.Second))
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, 30*time.Second, *timeout)
+	assert.Equal(t, 90*time.Second, *interval)
+	assert.Equal(t, 250*time.Millisecond, *delay)
+
+	Reset()
+	Duration("TIMEOUT", true, 0, "something")
+	assert.ErrorContains(t, Parse(), `time: missing unit in duration "30"`)
+	assert.Panics(t, func() { Int("TIMEOUT", true, 0, "something", DefaultUnit(time.Second)) })
+}
+
+func TestDefaultUnitOutOfRange(t *testing.T) {
+	for value, want := range map[string]string{
+		"NaN":   `invalid duration "NaN"`,
+		"Inf":   `invalid duration "Inf"`,
+		"-Inf":  `invalid duration "-Inf"`,
+		"1e300": `duration "1e300" out of range`,
+		"-1e20": `duration "-1e20" out of range`,
+	} {
+		Reset()
+		cleanup := setEnv("TIMEOUT", value)
+
+		Duration("TIMEOUT", true, 0, "something", DefaultUnit(time.Second))
+		assert.ErrorContains(t, Parse(), want, value)
+		cleanup()
+	}
+}
+
+func TestDeprecatedAlias(t *testing.T) {
+	Reset()
+	os.Unsetenv("LISTEN_ADDR")
+	cleanup := setEnv("BIND_ADDRESS", ":9090")
+	defer cleanup()
+
+	var warnings []string
+	SetLogger(func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	})
+	addr := String("LISTEN_ADDR", false, ":8080", "something", DeprecatedAlias("BIND_ADDRESS"))
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, ":9090", *addr)
+	assert.Equal(t, []string{"env: BIND_ADDRESS is deprecated, use LISTEN_ADDR instead"}, warnings)
+
+	// The new name takes precedence and no warning is logged.
+	warnings = nil
+	cleanup2 := setEnv("LISTEN_ADDR", ":7070")
+	defer cleanup2()
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, ":7070", *addr)
+	assert.Empty(t, warnings)
+}
+
+func TestDeprecatedAliasLogsOncePerParse(t *testing.T) {
+	Reset()
+	os.Unsetenv("LISTEN_ADDR")
+	cleanup := setEnv("BIND_ADDRESS", ":9090")
+	defer cleanup()
+
+	calls := 0
+	SetLogger(func(format string, args ...interface{}) {
+		calls++
+	})
+	// RequiredIf and the group look the variable up again while it is resolved.
+	String("LISTEN_ADDR", false, "", "something", DeprecatedAlias("BIND_ADDRESS"))
+	String("TLS_CERT", false, "", "something", RequiredIf("LISTEN_ADDR", ":443"))
+	String("SOCKET_PATH", false, "", "something")
+	ExactlyOneGroup("LISTEN_ADDR", "SOCKET_PATH")
+
+	assert.NoError(t, ParseFrom(func(name string) (string, bool) {
+		return os.LookupEnv(name)
+	}))
+	assert.Equal(t, 1, calls)
+
+	// No warning is logged when the alias does not supply the value.
+	calls = 0
+	cleanup2 := setEnv("LISTEN_ADDR", ":8080")
+	defer cleanup2()
+	assert.NoError(t, Parse())
+	assert.Equal(t, 0, calls)
+}
+
+func TestDeprecatedAliasQuietByDefault(t *testing.T) {
+	Reset()
+	os.Unsetenv("LISTEN_ADDR")
+	cleanup := setEnv("BIND_ADDRESS", ":9090")
+	defer cleanup()
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	addr := String("LISTEN_ADDR", false, ":8080", "something", DeprecatedAlias("BIND_ADDRESS"))
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, ":9090", *addr)
+	assert.Empty(t, buf.String())
+}
+
+func TestInvert(t *testing.T) {
+	Reset()
+	os.Unsetenv("DISABLE_METRICS")
+	cleanup := setEnv("DISABLE_CACHE", "true")
+	defer cleanup()
+	cleanup2 := setEnv("NO_COLOR", "yes")
+	defer cleanup2()
+
+	enableCache := Bool("DISABLE_CACHE", false, false, "something", Invert())
+	enableMetrics := Bool("DISABLE_METRICS", false, false, "something", Invert())
+	color := FlexBool("NO_COLOR", false, false, "something", Invert())
+
+	assert.NoError(t, Parse())
+	assert.False(t, *enableCache)
+	assert.True(t, *enableMetrics)
+	assert.False(t, *color)
+	assert.Contains(t, Help(), "DISABLE_METRICS type: boolean default: 'false'")
+	assert.Panics(t, func() { String("NAME", false, "", "something", Invert()) })
+}
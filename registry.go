@@ -0,0 +1,367 @@
+package env
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// Registry is an independent set of environment variables and the settings used to parse them.
+// The package-level functions use a default Registry, so a library can create its own Registry
+// to parse its variables without colliding with those of the host application.
+//
+// Example:
+//
+//	r := env.NewRegistry()
+//	r.SetPrefix("CACHE_")
+//	size := r.Int("SIZE", false, 128, "Number of cached entries")
+//	err := r.Parse()
+//
+// A Registry is safe for concurrent use, so variables may be registered from several goroutines.
+// Validators run while the Registry is locked and must not call its methods.
+type Registry struct {
+	mu   sync.Mutex
+	envs []envVar // The registered variables, in registration order.
+	settings
+}
+
+// settings controls how a Registry parses its variables.
+type settings struct {
+	prefix           string  // Prepended to every name when it is looked up in the environment.
+	prefixVar        string  // Environment variable the prefix is read from by Parse(), if not empty.
+	fileFallback     bool    // Whether NAME_FILE is read when NAME is unset.
+	caseInsensitive  bool    // Whether names are matched regardless of case.
+	trimSpace        bool    // Whether surrounding whitespace is trimmed from raw values.
+	groups           []group // Constraints on which combinations of variables may be set.
+	defaultDelimiter string  // Separator used by list constructors given an empty delimiter, or "," if empty.
+	strictPrefix     string  // Prefix of the environment variables that must all be registered, if not empty.
+	helpTrimPrefix   bool    // Whether Help and Usage list names without the prefix.
+	expand           bool    // Whether $VAR references in raw values are expanded.
+
+	// resolveOrder is the order sources are consulted in, or nil for defaultResolveOrder.
+	resolveOrder []ResolveSource
+
+	// yamlValues holds the flattened values loaded by LoadYAML, used when a variable is unset.
+	yamlValues map[string]string
+
+	// flags holds the flags bound by BindFlags, keyed by variable name.
+	flags map[string]*flagValue
+
+	// errorFormatter renders the message of each ParseError, if not nil.
+	errorFormatter func(name, varType, raw string, err error) string
+
+	// logger receives diagnostic messages, such as deprecation warnings, which are discarded if nil.
+	logger func(format string, args ...interface{})
+
+	// hook is called after each variable is resolved, if not nil.
+	hook func(name, varType string, fromEnv bool, rawValue string)
+
+	// lookupEnv replaces os.LookupEnv while ParseFrom is running.
+	lookupEnv func(string) (string, bool)
+}
+
+// defaultRegistry holds the variables registered with the package-level functions.
+var defaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		envs: make([]envVar, 0),
+	}
+}
+
+// reset clears every registered variable and setting of r.
+func (r *Registry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.envs = make([]envVar, 0)
+	r.settings = settings{}
+}
+
+// Unregister removes the variable registered under name, so that Parse() no longer reads it and it is
+// left out of Help() and Usage(). It returns whether a variable was removed. The pointer returned when the
+// variable was registered remains valid and keeps its current value.
+func Unregister(name string) bool {
+	return defaultRegistry.Unregister(name)
+}
+
+// Unregister is like the package-level Unregister but removes the variable from r.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.envs)
+	r.envs = slices.DeleteFunc(r.envs, func(e envVar) bool {
+		return e.name == name
+	})
+
+	return len(r.envs) != n
+}
+
+// Clone returns a copy of r, with its registered variables and settings, that can be parsed against a different
+// environment, i.e. with ParseFrom, without affecting r. Each variable of the copy has its own value, starting
+// from the current value in r, so that parsing one never changes the pointers returned by the constructors of
+// the other. The values of the copy are read with methods such as GetAll. Registering variables with the copy
+// or changing its settings does not affect r either.
+//
+// Example:
+//
+//	staging := env.NewRegistry()
+//	staging.String("API_URL", true, "", "Upstream API")
+//	production := staging.Clone()
+//	err := production.ParseFrom(productionLookup)
+func (r *Registry) Clone() *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := NewRegistry()
+	c.settings = r.settings
+	c.groups = slices.Clone(r.groups)
+	c.resolveOrder = slices.Clone(r.resolveOrder)
+	c.yamlValues = maps.Clone(r.yamlValues)
+	c.flags = maps.Clone(r.flags)
+	for _, e := range r.envs {
+		c.envs = append(c.envs, e.clone())
+	}
+
+	return c
+}
+
+// clone returns a copy of e with fresh pointers for its value, raw value and source, holding copies of
+// those of e, so that resolving the copy leaves e untouched.
+func (e envVar) clone() envVar {
+	value := reflect.New(reflect.TypeOf(e.value).Elem())
+	if current := reflect.ValueOf(deepCopy(e.get())); current.IsValid() {
+		value.Elem().Set(current)
+	}
+	e.value = value.Interface()
+
+	envValue, source := *e.envValue, *e.source
+	e.envValue, e.source = &envValue, &source
+
+	e.validators = slices.Clone(e.validators)
+	e.requiredIf = slices.Clone(e.requiredIf)
+	e.aliases = slices.Clone(e.aliases)
+
+	return e
+}
+
+// The sources Parse() can resolve a value from, as reported by Source.
+const (
+	sourceEnv     = "env"     // The process environment, or the lookup given to ParseFrom.
+	sourceFile    = "file"    // A file loaded by LoadFile, LoadReader or LoadYAML, or a NAME_FILE path.
+	sourceFlag    = "flag"    // A command-line flag bound by BindFlags.
+	sourceDefault = "default" // The default value.
+)
+
+// Source returns where the last Parse() resolved the variable registered under name from: "env" for the
+// environment, "file" for a value loaded by LoadFile, LoadReader or LoadYAML or read from a NAME_FILE path,
+// "flag" for a command-line flag bound by BindFlags, or "default". It returns "" if name is not registered
+// or could not be resolved. A value set in the environment after LoadFile loaded it is reported as "env".
+func Source(name string) string {
+	return defaultRegistry.Source(name)
+}
+
+// Source is like the package-level Source but reports on the variables registered with r.
+func (r *Registry) Source(name string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.envs {
+		if e.name == name {
+			return *e.source
+		}
+	}
+
+	return ""
+}
+
+// Defaulted returns the names of the variables whose value came from their default rather than the
+// environment during the last Parse(), in registration order. It is only meaningful after Parse()
+// has returned without an error.
+func Defaulted() []string {
+	return defaultRegistry.Defaulted()
+}
+
+// Defaulted is like the package-level Defaulted but reports on the variables registered with r.
+func (r *Registry) Defaulted() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0)
+	for _, e := range r.envs {
+		if *e.source == sourceDefault {
+			names = append(names, r.envName(e))
+		}
+	}
+
+	return names
+}
+
+// Summary returns counts describing the last Parse(), i.e. for a startup banner such as "12 variables: 9 from
+// env, 3 defaulted". total is the number of registered variables, fromEnv those resolved from the environment
+// or another source reported by Source such as a file or flag, fromDefault those that used their default, and
+// missing those that Parse() could not resolve because they were missing or invalid, so that the three add up
+// to total. Before the first Parse(), every variable counts as missing.
+func Summary() (total, fromEnv, fromDefault, missing int) {
+	return defaultRegistry.Summary()
+}
+
+// Summary is like the package-level Summary but reports on the variables registered with r.
+func (r *Registry) Summary() (total, fromEnv, fromDefault, missing int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.envs {
+		switch *e.source {
+		case sourceDefault:
+			fromDefault++
+		case "":
+			missing++
+		default:
+			fromEnv++
+		}
+	}
+
+	return len(r.envs), fromEnv, fromDefault, missing
+}
+
+// GetAll returns the current value of every registered variable keyed by name, i.e. to log the
+// effective configuration at startup. Values reflect what the last Parse() resolved from the
+// environment or defaults, except that secret variables are reported as "****".
+func GetAll() map[string]interface{} {
+	return defaultRegistry.GetAll()
+}
+
+// GetAll is like the package-level GetAll but reports on the variables registered with r.
+func (r *Registry) GetAll() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	all := make(map[string]interface{}, len(r.envs))
+	for _, e := range r.envs {
+		if e.secret {
+			all[r.envName(e)] = redacted
+			continue
+		}
+		all[r.envName(e)] = e.get()
+	}
+
+	return all
+}
+
+// Var describes a registered environment variable, i.e. for building a custom help renderer.
+type Var struct {
+	Name     string      // The name of the environment variable, including any prefix.
+	Type     string      // The data type, as shown by Help and Usage.
+	Help     string      // The description given when the variable was registered.
+	Required bool        // Whether the variable is always required.
+	Default  interface{} // The default value, or "****" for secrets.
+}
+
+// Vars returns a description of every registered variable, in registration order. The result is a copy,
+// so modifying it does not affect the registered variables.
+func Vars() []Var {
+	return defaultRegistry.Vars()
+}
+
+// Vars is like the package-level Vars but describes the variables registered with r.
+func (r *Registry) Vars() []Var {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	vars := make([]Var, 0, len(r.envs))
+	for _, e := range r.envs {
+		var def interface{} = redacted
+		if !e.secret {
+			def = deepCopy(e.defaultValue)
+		}
+
+		vars = append(vars, Var{
+			Name:     r.envName(e),
+			Type:     e.varType,
+			Help:     e.help,
+			Required: e.required,
+			Default:  def,
+		})
+	}
+
+	return vars
+}
+
+// deepCopy returns a copy of v that shares no map, slice or pointer with it, so that modifying the copy cannot
+// change v. Unexported struct fields are copied as is.
+func deepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	return copyValue(reflect.ValueOf(v), make(map[uintptr]reflect.Value)).Interface()
+}
+
+// copyValue returns a deep copy of v. seen maps the pointers already copied to their copies, so that
+// cyclic values are copied with the same cycles.
+func copyValue(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			c.SetMapIndex(iter.Key(), copyValue(iter.Value(), seen))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return c
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if c, ok := seen[v.Pointer()]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = c
+		c.Elem().Set(copyValue(v.Elem(), seen))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem(), seen))
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(copyValue(v.Field(i), seen))
+			}
+		}
+		return c
+	}
+
+	return v
+}
@@ -0,0 +1,236 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryIsIndependent(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1")
+	defer cleanup()
+	cleanupPrefixed := setEnv("LIB_nic", "2")
+	defer cleanupPrefixed()
+
+	r := NewRegistry()
+	r.SetPrefix("LIB_")
+	lib := r.Int("nic", true, 0, "something")
+	app := Int("nic", true, 0, "something")
+
+	assert.NoError(t, r.Parse())
+	assert.Equal(t, 2, *lib)
+	assert.Equal(t, 0, *app)
+	assert.Len(t, r.envs, 1)
+	assert.Len(t, defaultRegistry.envs, 1)
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, 1, *app)
+	assert.Contains(t, r.Help(), "LIB_nic")
+	assert.NotContains(t, Help(), "LIB_nic")
+}
+
+func TestRegistryErrors(t *testing.T) {
+	r := NewRegistry()
+	r.String("REGISTRY_MISSING", true, "", "something")
+	r.Bool("REGISTRY_MISSING_BOOL", false, true, "something")
+
+	err := r.Parse()
+
+	assert.ErrorContains(t, err, "expected: REGISTRY_MISSING type: string")
+}
+
+func TestJSONIn(t *testing.T) {
+	cleanup := setEnv("nic", `[1,2]`)
+	defer cleanup()
+
+	r := NewRegistry()
+	n := JSONIn(r, "nic", true, []int{}, "something")
+
+	assert.NoError(t, r.Parse())
+	assert.Equal(t, []int{1, 2}, *n)
+}
+
+func TestDefaulted(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1")
+	defer cleanup()
+	os.Unsetenv("other")
+	os.Unsetenv("third")
+
+	Int("nic", false, 0, "something")
+	String("other", false, "x", "something")
+	Bool("third", false, false, "something")
+
+	assert.Empty(t, Defaulted())
+	assert.NoError(t, Parse())
+	assert.Equal(t, []string{"other", "third"}, Defaulted())
+}
+
+func TestGetAll(t *testing.T) {
+	Reset()
+	cleanup := setEnv("nic", "1")
+	defer cleanup()
+	os.Unsetenv("other")
+
+	Int("nic", false, 0, "something")
+	String("other", false, "x", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, map[string]interface{}{"nic": 1, "other": "x"}, GetAll())
+}
+
+func TestRegistryConcurrentRegistration(t *testing.T) {
+	r := NewRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.Int(fmt.Sprintf("CONCURRENT_%d", i), false, i, "something")
+			r.Parse()
+			r.GetAll()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.NoError(t, r.Parse())
+	assert.Len(t, r.GetAll(), 50)
+}
+
+func TestUnregister(t *testing.T) {
+	Reset()
+	os.Unsetenv("PORT")
+	cleanup := setEnv("HOST", "example.com")
+	defer cleanup()
+
+	host := String("HOST", false, "localhost", "something")
+	port := Int("PORT", true, 0, "something")
+	assert.Error(t, Parse())
+
+	assert.True(t, Unregister("PORT"))
+	assert.False(t, Unregister("PORT"))
+	assert.NoError(t, Parse())
+	assert.Equal(t, "example.com", *host)
+	assert.Equal(t, 0, *port)
+
+	assert.True(t, Unregister("HOST"))
+	assert.Equal(t, "example.com", *host)
+}
+
+func TestVars(t *testing.T) {
+	Reset()
+	SetPrefix("APP_")
+
+	Int("PORT", false, 8080, "Port to listen on")
+	String("TOKEN", true, "dev-token", "API token", Secret())
+	StringSlice("HOSTS", false, []string{"a", "b"}, ",", "Hosts")
+
+	vars := Vars()
+	assert.Equal(t, []Var{
+		{Name: "APP_PORT", Type: "integer", Help: "Port to listen on", Default: 8080},
+		{Name: "APP_TOKEN", Type: "string", Help: "API token", Required: true, Default: "****"},
+		{Name: "APP_HOSTS", Type: "[]string", Help: "Hosts", Default: []string{"a", "b"}},
+	}, vars)
+
+	// Modifying the copy leaves the registrations untouched.
+	vars[2].Default.([]string)[0] = "changed"
+	vars[0].Name = "changed"
+	assert.Equal(t, "APP_PORT", Vars()[0].Name)
+	assert.Equal(t, []string{"a", "b"}, Vars()[2].Default)
+}
+
+func TestSource(t *testing.T) {
+	Reset()
+	os.Unsetenv("SOURCE_FILE")
+	os.Unsetenv("SOURCE_YAML")
+	os.Unsetenv("SOURCE_DEFAULT")
+	os.Unsetenv("SOURCE_CHANGED")
+	defer os.Unsetenv("SOURCE_FILE")
+	defer os.Unsetenv("SOURCE_CHANGED")
+	cleanup := setEnv("SOURCE_ENV", "env")
+	defer cleanup()
+
+	assert.NoError(t, LoadReader(strings.NewReader("SOURCE_FILE=file\nSOURCE_CHANGED=file\n"), false))
+	os.Setenv("SOURCE_CHANGED", "env")
+	assert.NoError(t, LoadYAML(strings.NewReader("source_yaml: yaml")))
+
+	String("SOURCE_ENV", false, "", "something")
+	String("SOURCE_FILE", false, "", "something")
+	String("SOURCE_YAML", false, "", "something")
+	String("SOURCE_CHANGED", false, "", "something")
+	String("SOURCE_DEFAULT", false, "default", "something")
+	Int("SOURCE_INVALID", false, 0, "something")
+
+	assert.Equal(t, "", Source("SOURCE_ENV"))
+
+	cleanup2 := setEnv("SOURCE_INVALID", "abc")
+	defer cleanup2()
+	assert.Error(t, Parse())
+	assert.Equal(t, "env", Source("SOURCE_ENV"))
+	assert.Equal(t, "file", Source("SOURCE_FILE"))
+	assert.Equal(t, "file", Source("SOURCE_YAML"))
+	assert.Equal(t, "env", Source("SOURCE_CHANGED"))
+	assert.Equal(t, "default", Source("SOURCE_DEFAULT"))
+	assert.Equal(t, "", Source("SOURCE_INVALID"))
+	assert.Equal(t, "", Source("UNKNOWN"))
+}
+
+func TestSummary(t *testing.T) {
+	Reset()
+	os.Unsetenv("TOKEN")
+	cleanup := setEnv("HOST", "example.com")
+	defer cleanup()
+	cleanup2 := setEnv("PORT", "abc")
+	defer cleanup2()
+
+	String("HOST", false, "localhost", "something")
+	Int("PORT", false, 8080, "something")
+	Int("WORKERS", false, 4, "something")
+	String("TOKEN", true, "", "something")
+
+	total, fromEnv, fromDefault, missing := Summary()
+	assert.Equal(t, []int{4, 0, 0, 4}, []int{total, fromEnv, fromDefault, missing})
+
+	assert.Error(t, Parse())
+	total, fromEnv, fromDefault, missing = Summary()
+	assert.Equal(t, []int{4, 1, 1, 2}, []int{total, fromEnv, fromDefault, missing})
+}
+
+func TestClone(t *testing.T) {
+	r := NewRegistry()
+	r.SetPrefix("APP_")
+	url := r.String("API_URL", true, "", "something")
+	origins := r.StringSlice("ORIGINS", false, []string{"a.com"}, ",", "something")
+
+	staging := map[string]string{"APP_API_URL": "https://staging", "APP_ORIGINS": "s.com"}
+	assert.NoError(t, r.ParseFrom(func(name string) (string, bool) {
+		v, ok := staging[name]
+		return v, ok
+	}))
+
+	c := r.Clone()
+	assert.Equal(t, r.GetAll(), c.GetAll())
+
+	production := map[string]string{"APP_API_URL": "https://production"}
+	assert.NoError(t, c.ParseFrom(func(name string) (string, bool) {
+		v, ok := production[name]
+		return v, ok
+	}))
+
+	// The original keeps its values, and the clone has its own.
+	assert.Equal(t, "https://staging", *url)
+	assert.Equal(t, []string{"s.com"}, *origins)
+	assert.Equal(t, "env", r.Source("ORIGINS"))
+	assert.Equal(t, map[string]interface{}{"APP_API_URL": "https://production", "APP_ORIGINS": []string{"a.com"}}, c.GetAll())
+	assert.Equal(t, "default", c.Source("ORIGINS"))
+
+	// Registering with the clone does not affect the original.
+	c.Int("WORKERS", false, 4, "something")
+	assert.Len(t, r.Vars(), 2)
+}
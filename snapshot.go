@@ -0,0 +1,41 @@
+package env
+
+import (
+	"os"
+	"strings"
+)
+
+// Snapshot captures the current process environment and returns a function that restores it, removing
+// variables that were added since and re-adding those that were changed or deleted. It is meant for tests
+// that set real environment variables, and combines with ParseFrom for tests that need no real variables.
+// Since the environment is shared by the whole process, tests that use it must not run in parallel.
+//
+// Example:
+//
+//	defer env.Snapshot()()
+//	os.Setenv("BIND_PORT", "9090")
+func Snapshot() func() {
+	saved := environ()
+
+	return func() {
+		for name := range environ() {
+			if _, ok := saved[name]; !ok {
+				os.Unsetenv(name)
+			}
+		}
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// environ returns the process environment as a map of names to values.
+func environ() map[string]string {
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		vars[name] = value
+	}
+
+	return vars
+}
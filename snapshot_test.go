@@ -0,0 +1,27 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	cleanup := setEnv("SNAPSHOT_CHANGED", "before")
+	defer cleanup()
+	cleanup2 := setEnv("SNAPSHOT_DELETED", "before")
+	defer cleanup2()
+	os.Unsetenv("SNAPSHOT_ADDED")
+
+	restore := Snapshot()
+	os.Setenv("SNAPSHOT_CHANGED", "after")
+	os.Unsetenv("SNAPSHOT_DELETED")
+	os.Setenv("SNAPSHOT_ADDED", "after")
+	restore()
+
+	assert.Equal(t, "before", os.Getenv("SNAPSHOT_CHANGED"))
+	assert.Equal(t, "before", os.Getenv("SNAPSHOT_DELETED"))
+	_, ok := os.LookupEnv("SNAPSHOT_ADDED")
+	assert.False(t, ok)
+}
@@ -0,0 +1,93 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetStrictUnknown makes Parse() return an error listing every environment variable whose name starts with
+// prefix but that is not registered, to catch typos like APP_BIND_PORR. Names are compared with the prefix
+// set by SetPrefix applied, and NAME_FILE counts as known when EnableFileFallback is in effect, as do the
+// numbered variables of IndexedStringSlice and the old names given to DeprecatedAlias. Strict mode is
+// disabled by default, and an empty prefix disables it again. It only applies to the process environment, not
+// to the lookup given to ParseFrom, which cannot be enumerated. Unmarshal counts both the fields of its struct
+// and the registered variables as known.
+//
+// Example:
+//
+//	env.SetPrefix("APP_")
+//	env.SetStrictUnknown("APP_")
+func SetStrictUnknown(prefix string) {
+	defaultRegistry.SetStrictUnknown(prefix)
+}
+
+// SetStrictUnknown is like the package-level SetStrictUnknown but applies to the variables registered with r.
+func (r *Registry) SetStrictUnknown(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.strictPrefix = prefix
+}
+
+// checkUnknown returns an error naming the environment variables that start with the strict prefix
+// but are neither registered nor among extra, or nil if there are none.
+func (r *Registry) checkUnknown(extra ...envVar) error {
+	if r.strictPrefix == "" || r.lookupEnv != nil {
+		return nil
+	}
+
+	known := make([]string, 0, len(r.envs))
+	indexed := make([]string, 0)
+	for _, e := range slices.Concat(r.envs, extra) {
+		known = append(known, r.envName(e))
+		if e.indexed {
+			indexed = append(indexed, r.envName(e)+"_")
+		}
+		for _, alias := range e.aliases {
+			known = append(known, r.prefix+alias)
+		}
+		if r.fileFallback {
+			known = append(known, r.envName(e)+"_FILE")
+		}
+	}
+
+	unknown := make([]string, 0)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, r.strictPrefix) || r.isKnown(name, known) || r.isIndexed(name, indexed) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	slices.Sort(unknown)
+	return fmt.Errorf("unknown environment variables with prefix %s: %s", r.strictPrefix, strings.Join(unknown, ", "))
+}
+
+// isKnown reports whether name matches one of known, ignoring case if r is case-insensitive.
+func (r *Registry) isKnown(name string, known []string) bool {
+	return slices.ContainsFunc(known, func(k string) bool {
+		if r.caseInsensitive {
+			return strings.EqualFold(k, name)
+		}
+		return k == name
+	})
+}
+
+// isIndexed reports whether name is one of the numbered variables, such as ARG_0, of the prefixes of
+// the variables registered with IndexedStringSlice, ignoring case if r is case-insensitive.
+func (r *Registry) isIndexed(name string, prefixes []string) bool {
+	return slices.ContainsFunc(prefixes, func(p string) bool {
+		if len(name) <= len(p) || !r.isKnown(name[:len(p)], []string{p}) {
+			return false
+		}
+		_, err := strconv.ParseUint(name[len(p):], 10, 64)
+		return err == nil
+	})
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStrictUnknown(t *testing.T) {
+	Reset()
+	cleanup := setEnv("STRICT_BIND_PORT", "9090")
+	defer cleanup()
+	cleanup2 := setEnv("STRICT_BIND_PORR", "9090")
+	defer cleanup2()
+	cleanup3 := setEnv("STRICT_HSOT", "localhost")
+	defer cleanup3()
+
+	SetPrefix("STRICT_")
+	Int("BIND_PORT", false, 8080, "something")
+
+	assert.NoError(t, Parse())
+
+	SetStrictUnknown("STRICT_")
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown environment variables with prefix STRICT_: STRICT_BIND_PORR, STRICT_HSOT")
+}
+
+func TestSetStrictUnknownFileFallback(t *testing.T) {
+	Reset()
+	cleanup := setEnv("STRICT_TOKEN_FILE", "/dev/null")
+	defer cleanup()
+
+	String("STRICT_TOKEN", false, "", "something")
+	SetStrictUnknown("STRICT_")
+	assert.Error(t, Parse())
+
+	EnableFileFallback()
+	assert.NoError(t, Parse())
+}
+
+func TestSetStrictUnknownIndexed(t *testing.T) {
+	Reset()
+	cleanup := setEnv("STRICT_ARG_0", "-v")
+	defer cleanup()
+	cleanup2 := setEnv("STRICT_ARG_X", "-q")
+	defer cleanup2()
+
+	IndexedStringSlice("STRICT_ARG", false, "something")
+	SetStrictUnknown("STRICT_")
+
+	err := Parse()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown environment variables with prefix STRICT_: STRICT_ARG_X")
+}
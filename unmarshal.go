@@ -0,0 +1,165 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Unmarshal populates the fields of the struct pointed to by v from environment variables, as an
+// alternative to registering package-level variables. Each field tagged with `env:"NAME"` is read
+// from the NAME environment variable using the same conversion as the matching constructor, and
+// untagged fields are left alone. The optional tags are:
+//
+//   - required:"true": the variable must be set.
+//   - default:"...": the value to use when the variable is not set, written as it would be in the environment.
+//   - help:"...": a description of the variable.
+//
+// Supported field kinds are string, bool, int, int32, int64, uint, uint64, float32, float64,
+// time.Duration and []string. Fields of any other kind cause an error. The fields are not added to
+// the global registrations, so they do not appear in Help(). As with Parse(), a field whose variable
+// fails to convert or validate keeps its previous value, while the other fields are still set and the
+// errors are returned.
+//
+// Example:
+//
+//	var cfg struct {
+//		Port    int           `env:"PORT" default:"8080" help:"HTTP server port"`
+//		APIKey  string        `env:"API_KEY" required:"true" help:"Upstream API key"`
+//		Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+//	}
+//	err := env.Unmarshal(&cfg)
+func Unmarshal(v interface{}) error {
+	return defaultRegistry.Unmarshal(v)
+}
+
+// ParseInto is the generic counterpart of Unmarshal: it populates the struct of type T pointed to by ptr
+// from environment variables, using the same tags and conversions. The type parameter only accepts pointers
+// of the struct's own type, so the result is typed at compile time. A ptr that is nil or does not point to a
+// struct, or a tagged field of an unsupported type, causes an error naming the problem.
+//
+// Example:
+//
+//	type Config struct {
+//		Port int `env:"PORT" default:"8080"`
+//	}
+//	var cfg Config
+//	err := env.ParseInto(&cfg)
+func ParseInto[T any](ptr *T) error {
+	return ParseIntoIn(defaultRegistry, ptr)
+}
+
+// ParseIntoIn is like ParseInto but uses the settings of r, as Registry.Unmarshal does. It is a function
+// rather than a Registry method because methods cannot have type parameters.
+func ParseIntoIn[T any](r *Registry, ptr *T) error {
+	if ptr == nil || reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return fmt.Errorf("env: ParseInto expects a non-nil pointer to a struct, got %T", ptr)
+	}
+
+	return r.Unmarshal(ptr)
+}
+
+// Unmarshal is like the package-level Unmarshal but uses the settings of r, such as its prefix.
+// The fields are not added to r, and the groups and hook of r do not apply to them.
+func (r *Registry) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("env: Unmarshal expects a non-nil pointer to a struct, got %T", v)
+	}
+
+	// Register the fields with a Registry that shares the settings of r, so the registrations of r are untouched.
+	// Groups and the hook concern the variables registered with r, not the fields, so they are left out.
+	tmp := NewRegistry()
+	r.mu.Lock()
+	tmp.settings = r.settings
+	registered := slices.Clone(r.envs)
+	r.mu.Unlock()
+	tmp.groups = nil
+	tmp.hook = nil
+
+	// Check for unknown variables separately, so that those registered with r count as known too.
+	strictPrefix := tmp.strictPrefix
+	tmp.strictPrefix = ""
+
+	sv := rv.Elem()
+	fields := make([]reflect.Value, 0)
+	for i := 0; i < sv.NumField(); i++ {
+		f := sv.Type().Field(i)
+		name, ok := f.Tag.Lookup("env")
+		if !ok || !f.IsExported() {
+			continue
+		}
+
+		if err := tmp.registerField(f, name); err != nil {
+			return err
+		}
+		fields = append(fields, sv.Field(i))
+	}
+
+	err := tmp.processEnvVars(context.Background())
+	tmp.strictPrefix = strictPrefix
+	if unknown := tmp.checkUnknown(registered...); unknown != nil {
+		err = errors.Join(err, unknown)
+	}
+
+	// Copy the resolved values into the struct, converting to the field's own type. A field whose variable
+	// failed has no source and keeps its previous value.
+	for i, f := range fields {
+		if *tmp.envs[i].source == "" {
+			continue
+		}
+		f.Set(reflect.ValueOf(tmp.envs[i].value).Elem().Convert(f.Type()))
+	}
+
+	return err
+}
+
+// registerField registers the struct field f with r under name using the constructor for its kind,
+// and converts its default tag with that constructor's conversion.
+func (r *Registry) registerField(f reflect.StructField, name string) error {
+	required := f.Tag.Get("required") == "true"
+	help := f.Tag.Get("help")
+
+	switch {
+	case f.Type == durationType:
+		r.Duration(name, required, 0, help)
+	case f.Type.Kind() == reflect.String:
+		r.String(name, required, "", help)
+	case f.Type.Kind() == reflect.Bool:
+		r.Bool(name, required, false, help)
+	case f.Type.Kind() == reflect.Int:
+		r.Int(name, required, 0, help)
+	case f.Type.Kind() == reflect.Int32:
+		r.Int32(name, required, 0, help)
+	case f.Type.Kind() == reflect.Int64:
+		r.Int64(name, required, 0, help)
+	case f.Type.Kind() == reflect.Uint:
+		r.Uint(name, required, 0, help)
+	case f.Type.Kind() == reflect.Uint64:
+		r.Uint64(name, required, 0, help)
+	case f.Type.Kind() == reflect.Float32:
+		r.Float32(name, required, 0, help)
+	case f.Type.Kind() == reflect.Float64:
+		r.Float64(name, required, 0, help)
+	case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+		r.StringSlice(name, required, nil, "", help)
+	default:
+		return fmt.Errorf("env: field %s has unsupported type %s", f.Name, f.Type)
+	}
+
+	// Convert the default tag the same way an environment value would be converted.
+	if def, ok := f.Tag.Lookup("default"); ok {
+		e := &r.envs[len(r.envs)-1]
+		if err := e.setValue(e.value, def); err != nil {
+			return fmt.Errorf("env: invalid default for field %s: %w", f.Name, err)
+		}
+		e.defaultValue = reflect.ValueOf(e.value).Elem().Interface()
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package env
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshal(t *testing.T) {
+	Reset()
+	cleanupHost := setEnv("UM_HOST", "example.com")
+	defer cleanupHost()
+	cleanupTags := setEnv("UM_TAGS", "a,b")
+	defer cleanupTags()
+	os.Unsetenv("UM_PORT")
+
+	type port int
+	var cfg struct {
+		Host    string        `env:"UM_HOST" required:"true" help:"server host"`
+		Port    port          `env:"UM_PORT" default:"8080"`
+		Debug   bool          `env:"UM_DEBUG" default:"true"`
+		Ratio   float64       `env:"UM_RATIO" default:"0.5"`
+		Timeout time.Duration `env:"UM_TIMEOUT" default:"2s"`
+		Tags    []string      `env:"UM_TAGS"`
+		Ignored string
+	}
+
+	err := Unmarshal(&cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, port(8080), cfg.Port)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 0.5, cfg.Ratio)
+	assert.Equal(t, 2*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Empty(t, defaultRegistry.envs)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	Reset()
+	os.Unsetenv("UM_HOST")
+	cleanup := setEnv("UM_PORT", "a")
+	defer cleanup()
+
+	var cfg struct {
+		Host string `env:"UM_HOST" required:"true"`
+		Port int    `env:"UM_PORT"`
+	}
+
+	err := Unmarshal(&cfg)
+
+	assert.Contains(t, err.Error(), "expected: UM_HOST type: string")
+	assert.Contains(t, err.Error(), "expected: UM_PORT type: integer got: a")
+}
+
+func TestUnmarshalKeepsFailedFields(t *testing.T) {
+	Reset()
+	cleanup := setEnv("UM_PORT", "zz")
+	defer cleanup()
+	os.Unsetenv("UM_HOST")
+
+	cfg := struct {
+		Port int    `env:"UM_PORT" default:"3"`
+		Host string `env:"UM_HOST" default:"localhost"`
+	}{Port: 7}
+
+	err := Unmarshal(&cfg)
+
+	assert.ErrorContains(t, err, "expected: UM_PORT type: integer got: zz")
+	assert.Equal(t, 7, cfg.Port)
+	assert.Equal(t, "localhost", cfg.Host)
+}
+
+func TestUnmarshalUnsupported(t *testing.T) {
+	var cfg struct {
+		Ch chan int `env:"UM_CH"`
+	}
+
+	assert.ErrorContains(t, Unmarshal(&cfg), "field Ch has unsupported type chan int")
+	assert.ErrorContains(t, Unmarshal(cfg), "pointer to a struct")
+}
+
+func TestUnmarshalInvalidDefault(t *testing.T) {
+	var cfg struct {
+		Port int `env:"UM_PORT" default:"eighty"`
+	}
+
+	assert.ErrorContains(t, Unmarshal(&cfg), "invalid default for field Port")
+}
+
+func TestParseInto(t *testing.T) {
+	Reset()
+	cleanup := setEnv("PORT", "9090")
+	defer cleanup()
+
+	type config struct {
+		Port    int           `env:"PORT" default:"8080"`
+		Timeout time.Duration `env:"TIMEOUT" default:"30s"`
+	}
+
+	var cfg config
+	assert.NoError(t, ParseInto(&cfg))
+	assert.Equal(t, config{Port: 9090, Timeout: 30 * time.Second}, cfg)
+}
+
+func TestParseIntoInvalid(t *testing.T) {
+	Reset()
+
+	var n int
+	assert.EqualError(t, ParseInto(&n), "env: ParseInto expects a non-nil pointer to a struct, got *int")
+	assert.Error(t, ParseInto[struct{}](nil))
+
+	var cfg struct {
+		Ratio complex128 `env:"RATIO"`
+	}
+	assert.ErrorContains(t, ParseInto(&cfg), "field Ratio has unsupported type complex128")
+}
+
+func TestUnmarshalIgnoresRegistryGroupsAndHook(t *testing.T) {
+	Reset()
+	cleanup := setEnv("A_URL", "https://a")
+	defer cleanup()
+	cleanup2 := setEnv("A_HOST", "a")
+	defer cleanup2()
+	cleanup3 := setEnv("UM_HOST", "example.com")
+	defer cleanup3()
+
+	String("A_URL", false, "", "something")
+	String("A_HOST", false, "", "something")
+	ExactlyOneGroup("A_URL", "A_HOST")
+	calls := 0
+	SetHook(func(name, varType string, fromEnv bool, rawValue string) {
+		calls++
+	})
+
+	var cfg struct {
+		Host string `env:"UM_HOST"`
+	}
+	assert.NoError(t, Unmarshal(&cfg))
+	assert.Equal(t, "example.com", cfg.Host)
+	assert.Equal(t, 0, calls)
+	assert.Error(t, Parse())
+}
+
+func TestUnmarshalStrictUnknown(t *testing.T) {
+	Reset()
+	cleanup := setEnv("APPX_NAME", "registered")
+	defer cleanup()
+	cleanup2 := setEnv("APPX_HOST", "example.com")
+	defer cleanup2()
+	cleanup3 := setEnv("APPX_HSOT", "typo")
+	defer cleanup3()
+
+	String("APPX_NAME", false, "", "something")
+	SetStrictUnknown("APPX_")
+
+	var cfg struct {
+		Host string `env:"APPX_HOST"`
+	}
+	err := Unmarshal(&cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown environment variables with prefix APPX_: APPX_HSOT")
+	assert.NotContains(t, err.Error(), "APPX_NAME")
+	assert.Equal(t, "example.com", cfg.Host)
+}
@@ -0,0 +1,112 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Usage writes a table describing every registered environment variable to w, with its name, type,
+// whether it is required, its default value and its help text in aligned columns. Parse() writes
+// this to standard output when the --help flag is set.
+func Usage(w io.Writer) {
+	defaultRegistry.Usage(w)
+}
+
+// Usage is like the package-level Usage but describes the variables registered with r.
+func (r *Registry) Usage(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, r.helpTitle())
+	fmt.Fprintln(tw, "  NAME\tTYPE\tREQUIRED\tDEFAULT\tDESCRIPTION")
+	for _, e := range r.envs {
+		// Mark required variables so operators can see what they must provide.
+		required := ""
+		if e.required {
+			required = "yes"
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", r.helpName(e), e.varType, required, quoteDefault(e), e.help)
+	}
+
+	tw.Flush()
+}
+
+// SetHelpTrimPrefix controls whether Help and Usage list variables without the prefix set by SetPrefix,
+// which is then stated once in their header instead, i.e. "Environment variables (prefix APP_):". It is
+// disabled by default. Other output, such as Vars, GetAll and errors from Parse(), always uses the full names.
+func SetHelpTrimPrefix(enabled bool) {
+	defaultRegistry.SetHelpTrimPrefix(enabled)
+}
+
+// SetHelpTrimPrefix is like the package-level SetHelpTrimPrefix but applies to the variables registered with r.
+func (r *Registry) SetHelpTrimPrefix(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.helpTrimPrefix = enabled
+}
+
+// helpTitle returns the header of Help and Usage, naming the prefix if it is trimmed from the variable names.
+func (r *Registry) helpTitle() string {
+	if r.helpTrimPrefix && r.prefix != "" {
+		return "Environment variables (prefix " + r.prefix + "):"
+	}
+
+	return "Environment variables:"
+}
+
+// helpName returns the name of e as listed by Help and Usage.
+func (r *Registry) helpName(e envVar) string {
+	if r.helpTrimPrefix {
+		return e.name
+	}
+
+	return r.envName(e)
+}
+
+// PrintConfig writes the resolved configuration to w, i.e. to log it at startup, as a table with the name,
+// current value and source of every registered variable in aligned columns. The source is one of those
+// reported by Source, so values that came from defaults are shown as "default". Secret values are shown
+// as "****". It is meant to be called after Parse().
+//
+// Example:
+//
+//	env.MustParse()
+//	env.PrintConfig(os.Stderr)
+func PrintConfig(w io.Writer) {
+	defaultRegistry.PrintConfig(w)
+}
+
+// PrintConfig is like the package-level PrintConfig but describes the variables registered with r.
+func (r *Registry) PrintConfig(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE")
+	for _, e := range r.envs {
+		value := e.formatCurrent()
+		if e.secret && value != "" {
+			value = redacted
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.envName(e), value, *e.source)
+	}
+
+	tw.Flush()
+}
+
+// quoteDefault quotes the default value of e, or returns "no default" if it is empty.
+func quoteDefault(e envVar) string {
+	def := "'" + e.formatDefault() + "'"
+	if def == "''" {
+		def = "no default"
+	}
+
+	return def
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage(t *testing.T) {
+	Reset()
+	String("SERVER_URI", true, "localhost:8181", "URI for upstream server")
+	Int("TIMEOUT", false, 12, "Timeout duration in seconds")
+	String("API_KEY", false, "", "API key for upstream server")
+
+	var b strings.Builder
+	Usage(&b)
+
+	assert.Equal(t, `Environment variables:
+  NAME        TYPE     REQUIRED  DEFAULT           DESCRIPTION
+  SERVER_URI  string   yes       'localhost:8181'  URI for upstream server
+  TIMEOUT     integer            '12'              Timeout duration in seconds
+  API_KEY     string             no default        API key for upstream server
+`, b.String())
+}
+
+func TestPrintConfig(t *testing.T) {
+	Reset()
+	cleanup := setEnv("API_URL", "https://api.example.com/v1")
+	defer cleanup()
+	cleanup2 := setEnv("API_TOKEN", "hunter2")
+	defer cleanup2()
+
+	URL("API_URL", true, "", "something")
+	String("API_TOKEN", true, "", "something", Secret())
+	Duration("TIMEOUT", false, 5*time.Second, "something")
+	StringSlice("HOSTS", false, []string{"a", "b"}, ",", "something")
+	assert.NoError(t, Parse())
+
+	var b strings.Builder
+	PrintConfig(&b)
+	assert.Equal(t, `NAME       VALUE                       SOURCE
+API_URL    https://api.example.com/v1  env
+API_TOKEN  ****                        env
+TIMEOUT    5s                          default
+HOSTS      a,b                         default
+`, b.String())
+}
+
+func TestSetHelpTrimPrefix(t *testing.T) {
+	Reset()
+	SetPrefix("APP_")
+	SetHelpTrimPrefix(true)
+
+	Int("PORT", false, 8080, "Port to listen on")
+
+	var b strings.Builder
+	Usage(&b)
+	assert.Equal(t, `Environment variables (prefix APP_):
+  NAME  TYPE     REQUIRED  DEFAULT  DESCRIPTION
+  PORT  integer            '8080'   Port to listen on
+`, b.String())
+	assert.Contains(t, Help(), "Environment variables (prefix APP_):\n  PORT type: integer default: '8080'")
+	assert.Equal(t, "APP_PORT", Vars()[0].Name)
+}
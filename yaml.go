@@ -0,0 +1,98 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadYAML reads a YAML document from r and uses it as a source of fallback values that Parse() consults
+// when a variable is not set in the environment. Real environment variables always take precedence.
+//
+// The document is flattened into UPPER_SNAKE names, so that nested keys such as
+//
+//	db:
+//	  host: localhost
+//
+// provide DB_HOST. Characters other than letters and digits become underscores, lists of scalars are
+// joined with commas, and other lists are flattened by index, i.e. SERVERS_0_HOST. Keys are matched
+// against the same names as the environment, including any prefix. Later calls add to and override
+// the values of earlier ones.
+func LoadYAML(r io.Reader) error {
+	return defaultRegistry.LoadYAML(r)
+}
+
+// LoadYAML is like the package-level LoadYAML but applies to the variables registered with reg.
+func (reg *Registry) LoadYAML(r io.Reader) error {
+	var doc interface{}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && err != io.EOF {
+		return err
+	}
+
+	values := make(map[string]string)
+	flattenYAML("", doc, values)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	// Copy rather than modify the existing values, since Unmarshal shares them with temporary registries.
+	merged := maps.Clone(reg.yamlValues)
+	if merged == nil {
+		merged = make(map[string]string)
+	}
+	maps.Copy(merged, values)
+	reg.yamlValues = merged
+
+	return nil
+}
+
+// flattenYAML adds the scalar values found in v to values, keyed by their UPPER_SNAKE path below key.
+func flattenYAML(key string, v interface{}, values map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			flattenYAML(joinYAMLKey(key, k), child, values)
+		}
+
+	case []interface{}:
+		// Join lists of scalars with commas, quoted as StringSlice expects, and index anything else.
+		scalars := make([]string, 0, len(v))
+		for _, child := range v {
+			switch child.(type) {
+			case map[string]interface{}, []interface{}:
+				for i, child := range v {
+					flattenYAML(joinYAMLKey(key, fmt.Sprint(i)), child, values)
+				}
+				return
+			}
+			scalars = append(scalars, quoteElement(fmt.Sprint(child), ","))
+		}
+		values[key] = strings.Join(scalars, ",")
+
+	case nil:
+		values[key] = ""
+
+	default:
+		values[key] = fmt.Sprint(v)
+	}
+}
+
+// joinYAMLKey appends the UPPER_SNAKE form of k to key.
+func joinYAMLKey(key, k string) string {
+	k = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, k)
+
+	if key == "" {
+		return k
+	}
+
+	return key + "_" + k
+}
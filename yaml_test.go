@@ -0,0 +1,57 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadYAML(t *testing.T) {
+	Reset()
+	os.Unsetenv("DB_HOST")
+	os.Unsetenv("DB_PORT")
+	os.Unsetenv("ORIGINS")
+	cleanup := setEnv("LOG_LEVEL", "debug")
+	defer cleanup()
+
+	err := LoadYAML(strings.NewReader(`
+db:
+  host: db.internal
+  port: 5432
+log-level: info
+origins: [a.com, b.com]
+`))
+	assert.NoError(t, err)
+
+	host := String("DB_HOST", true, "", "something")
+	port := Int("DB_PORT", false, 0, "something")
+	level := String("LOG_LEVEL", false, "", "something")
+	origins := StringSlice("ORIGINS", false, nil, ",", "something")
+
+	assert.NoError(t, Parse())
+	assert.Equal(t, "db.internal", *host)
+	assert.Equal(t, 5432, *port)
+	assert.Equal(t, "debug", *level)
+	assert.Equal(t, []string{"a.com", "b.com"}, *origins)
+}
+
+func TestFlattenYAML(t *testing.T) {
+	values := make(map[string]string)
+	flattenYAML("", map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b"},
+		},
+		"empty": nil,
+	}, values)
+
+	assert.Equal(t, map[string]string{"SERVERS_0_HOST": "a", "SERVERS_1_HOST": "b", "EMPTY": ""}, values)
+}
+
+func TestLoadYAMLError(t *testing.T) {
+	Reset()
+
+	assert.Error(t, LoadYAML(strings.NewReader("a: [")))
+}